@@ -3,58 +3,33 @@ package asset
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/anaminus/rbxweb"
+	"github.com/anaminus/rbxweb/catalog"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
-// Asset types.
-const (
-	TypeImage        byte = 1
-	TypeTShirt       byte = 2
-	TypeAudio        byte = 3
-	TypeMesh         byte = 4
-	TypeLua          byte = 5
-	TypeHTML         byte = 6
-	TypeText         byte = 7
-	TypeHat          byte = 8
-	TypePlace        byte = 9
-	TypeModel        byte = 10
-	TypeShirt        byte = 11
-	TypePants        byte = 12
-	TypeDecal        byte = 13
-	TypeAvatar       byte = 16
-	TypeHead         byte = 17
-	TypeFace         byte = 18
-	TypeGear         byte = 19
-	TypeBadge        byte = 21
-	TypeGroupEmblem  byte = 22
-	TypeAnimation    byte = 24
-	TypeArms         byte = 25
-	TypeLegs         byte = 26
-	TypeTorso        byte = 27
-	TypeRightArm     byte = 28
-	TypeLeftArm      byte = 29
-	TypeLeftLeg      byte = 30
-	TypeRightLeg     byte = 31
-	TypePackage      byte = 32
-	TypeYouTubeVideo byte = 33
-	TypeGamePass     byte = 34
-	TypeApp          byte = 35
-	TypeCode         byte = 37
-	TypePlugin       byte = 38
-)
-
 // GetLatestModel returns the asset id of the latest model for a given user.
 // While this is useful for retrieving the asset id of a newly created model
-// that was just uploaded, it is not necessarily reliable for this purpose.
+// that was just uploaded, it is not necessarily reliable for this purpose. If
+// the user has no models, the returned error matches rbxweb.ErrNotFound.
 func GetLatestModel(client *rbxweb.Client, userId int32) (assetId int64, err error) {
+	return GetLatestModelContext(context.Background(), client, userId)
+}
+
+// GetLatestModelContext is similar to GetLatestModel, but the request is
+// canceled when `ctx` is done.
+func GetLatestModelContext(ctx context.Context, client *rbxweb.Client, userId int32) (assetId int64, err error) {
 	if userId == 0 {
 		return 0, errors.New("invalid user id")
 	}
@@ -66,7 +41,11 @@ func GetLatestModel(client *rbxweb.Client, userId int32) (assetId int64, err err
 		"ResultsPerPage":    {"1"},
 		"CreatorID":         {strconv.FormatInt(int64(userId), 10)},
 	}
-	resp, err := client.Get(client.GetURL(`api`, `/catalog/json`, query))
+	req, err := client.NewRequest(ctx, rbxweb.EndpointLatestModel, query, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return 0, err
 	}
@@ -79,158 +58,462 @@ func GetLatestModel(client *rbxweb.Client, userId int32) (assetId int64, err err
 	if err = dec.Decode(&asset); err != nil {
 		return 0, errors.New("JSON decode failed: " + err.Error())
 	}
+	if len(asset) == 0 {
+		return 0, fmt.Errorf("user %d has no models: %w", userId, rbxweb.ErrNotFound)
+	}
 	return asset[0].AssetId, nil
 }
 
 // GetIdFromVersion returns an asset id from an asset version id.
 func GetIdFromVersion(client *rbxweb.Client, assetVersionId int64) (assetId int64, err error) {
+	return GetIdFromVersionContext(context.Background(), client, assetVersionId)
+}
+
+// GetIdFromVersionContext is similar to GetIdFromVersion, but the request is
+// canceled when `ctx` is done.
+func GetIdFromVersionContext(ctx context.Context, client *rbxweb.Client, assetVersionId int64) (assetId int64, err error) {
 	query := url.Values{
 		"avid": {strconv.FormatInt(assetVersionId, 10)},
 	}
 
 	// This relies on how asset names are converted to url names. Currently,
 	// if an asset name is "_", its url becomes "unnamed".
-	req, _ := http.NewRequest("HEAD", client.GetURL(`www`, `/_-item`, query), nil)
+	req, err := client.NewRequest(ctx, rbxweb.EndpointAssetItem, query, nil)
+	if err != nil {
+		return 0, err
+	}
 	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return 0, err
 	}
 	resp.Body.Close()
 
-	values, err := url.ParseQuery(resp.Header.Get("Location"))
-	if err = client.AssertResp(resp, err); err != nil {
-		return 0, err
+	// The item page is found through a redirect. If the client followed the
+	// redirect, then the id is in the final URL, otherwise it is in the
+	// location of the redirect.
+	location := resp.Request.URL
+	if loc := resp.Header.Get("Location"); loc != "" {
+		if location, err = resp.Request.URL.Parse(loc); err != nil {
+			return 0, err
+		}
+	}
+	id := location.Query().Get("id")
+	if id == "" {
+		return 0, errors.New("asset id not found in item URL")
 	}
+	return strconv.ParseInt(id, 10, 64)
+}
 
-	return strconv.ParseInt(values.Get("id"), 10, 64)
+// UploadOptions specifies how an asset is uploaded with Upload.
+//
+// Type is the type of asset, which is one of the Type constants. AssetId is
+// the id of the asset to update, or 0 to upload a new asset.
+//
+// The remaining fields describe the asset, and only apply to new assets.
+// That is, updating an asset will only update the contents, but not the
+// information about it. Name and Description are the name and description of
+// the asset. Genre is the genre of the asset, which is one of the Genre
+// constants from the catalog package, or 0 to leave the genre unset.
+// IsPublic sets whether the asset can be taken by other users, and
+// AllowComments sets whether users can comment on the asset.
+//
+// Progress, if not nil, is called as the data is sent, with the number of
+// bytes sent so far, and the total number of bytes, which is -1 if not
+// known. If the request is sent again, such as when it is retried, then the
+// count starts over.
+//
+// Compress sets whether the data is compressed with gzip as it is sent. This
+// is ignored if the upload endpoint does not accept compressed data, as
+// indicated by the Gzip field of the endpoint. Progress counts bytes before
+// they are compressed.
+type UploadOptions struct {
+	Type          AssetType
+	AssetId       int64
+	Name          string
+	Description   string
+	Genre         byte
+	IsPublic      bool
+	AllowComments bool
+	Progress      func(sent int64, total int64)
+	Compress      bool
+}
+
+// Validate returns an error if the options cannot describe a valid upload.
+func (opts UploadOptions) Validate() error {
+	if !opts.Type.Valid() {
+		return errors.New("invalid asset type " + strconv.Itoa(int(opts.Type)))
+	}
+	if opts.AssetId < 0 {
+		return errors.New("invalid asset id " + strconv.FormatInt(opts.AssetId, 10))
+	}
+	if opts.Genre != 0 && !catalog.ValidGenre(opts.Genre) {
+		return errors.New("invalid genre " + strconv.Itoa(int(opts.Genre)))
+	}
+	return nil
+}
+
+// values converts the options to the query parameters of the upload service.
+func (opts UploadOptions) values() url.Values {
+	query := url.Values{
+		"type":    {opts.Type.String()},
+		"assetid": {strconv.FormatInt(opts.AssetId, 10)},
+	}
+	if opts.AssetId != 0 {
+		return query
+	}
+	if opts.Name != "" {
+		query.Set("name", opts.Name)
+	}
+	if opts.Description != "" {
+		query.Set("description", opts.Description)
+	}
+	if opts.Genre != 0 {
+		query.Set("genreTypeId", strconv.Itoa(int(opts.Genre)))
+	}
+	query.Set("isPublic", formatBool(opts.IsPublic))
+	query.Set("allowComments", formatBool(opts.AllowComments))
+	return query
+}
+
+// formatBool formats a boolean in the style expected by the website.
+func formatBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
 }
 
 // Upload generically uploads data from `reader` as an asset to the ROBLOX
-// website. `info` can be used to specify information about the model. The
-// following parameters are known:
+// website. `opts` specifies the type of the asset, and information about it.
+// An error is returned without sending a request if `opts` is not valid.
 //
-//     type           - The type of asset.
-//     assetid        - The id of the asset to update. 0 uploads a new asset.
-//     name           - The name of the asset.
-//     description    - The asset's description.
-//     genreTypeId    - The asset's genre.
-//     isPublic       - Whether the asset can be taken by other users.
-//     allowComments  - Whether users can comment on the asset.
+// The data is streamed from `reader` as it is sent. If the size of the data
+// can be determined, such as when `reader` is an *os.File or *bytes.Reader,
+// then the length of the request is set. If `reader` is an io.Seeker, or
+// holds its content in memory like a *bytes.Buffer, then the request can be
+// sent again when necessary, such as when it is retried, or when the website
+// responds with a new CSRF token. A *bytes.Buffer is not drained by the
+// upload.
 //
-// The success of this function is highly dependent on these parameters. For
+// Other readers, such as pipes, can be sent only once. If the client has no
+// CSRF token yet, then one is obtained with FetchCSRFToken before any data is
+// read, so that the data is sent with a token.
+//
+// The success of this function is highly dependent on these options. For
 // example, most asset types may only be uploaded by authorized users.
-// Parameters that specify information about the asset only apply for new
-// assets. That is, updating an asset will only update the contents, but not
-// the information about it.
 //
 // `assetVersionId` is the version id of the uploaded asset. This is unique
 // for each upload. This can be used with GetIdFromVersion to get the asset
-// id.
+// id. If the website does not accept the upload, then an *UploadError is
+// returned.
 //
 // This function requires the client to be logged in.
-func Upload(client *rbxweb.Client, reader io.Reader, info url.Values) (assetVersionId int64, err error) {
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(reader)
-	req, _ := http.NewRequest("POST", client.GetURL(`www`, `/Data/Upload.ashx`, info), buf)
-	req.Header.Set("User-Agent", "roblox/rbxweb")
+func Upload(client *rbxweb.Client, reader io.Reader, opts UploadOptions) (assetVersionId int64, err error) {
+	return UploadContext(context.Background(), client, reader, opts)
+}
+
+// UploadContext is similar to Upload, but the request is canceled when `ctx`
+// is done.
+func UploadContext(ctx context.Context, client *rbxweb.Client, reader io.Reader, opts UploadOptions) (assetVersionId int64, err error) {
+	return upload(ctx, client, reader, opts, "roblox/rbxweb")
+}
+
+// upload sends an upload request with the given user agent.
+func upload(ctx context.Context, client *rbxweb.Client, reader io.Reader, opts UploadOptions, userAgent string) (assetVersionId int64, err error) {
+	if err = opts.Validate(); err != nil {
+		return 0, err
+	}
+	req, err := client.NewRequest(ctx, rbxweb.EndpointUpload, opts.values(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	endpoint, _ := client.Endpoint(rbxweb.EndpointUpload)
+	if err = setUploadBody(req, reader, opts.Progress, opts.Compress && endpoint.Gzip); err != nil {
+		return 0, err
+	}
+	// A body that cannot be rewound cannot be sent again with a new token.
+	if req.GetBody == nil && req.Body != http.NoBody {
+		if err = client.FetchCSRFToken(ctx, rbxweb.EndpointUpload); err != nil {
+			req.Body.Close()
+			return 0, err
+		}
+	}
 
 	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
-		return 0, err
+		return 0, uploadStatusError(err)
 	}
 	defer resp.Body.Close()
 
+	// A successful upload responds with only the version id. Anything else,
+	// such as an error message or page, indicates a failure.
 	r := new(bytes.Buffer)
-	r.ReadFrom(resp.Body)
-	assetVersionId, _ = strconv.ParseInt(r.String(), 10, 64)
+	if _, err = r.ReadFrom(io.LimitReader(resp.Body, 4096)); err != nil {
+		return 0, err
+	}
+	assetVersionId, err = strconv.ParseInt(strings.TrimSpace(r.String()), 10, 64)
+	if err != nil || assetVersionId <= 0 {
+		return 0, newUploadError(resp.StatusCode, r.String(), nil)
+	}
+	return assetVersionId, nil
+}
+
+// progressReader reports the number of bytes read from a reader.
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress func(sent int64, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (n int, err error) {
+	n, err = p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent, p.total)
+	}
+	return n, err
+}
+
+// readerSize returns the number of bytes remaining in a reader, or -1 if it
+// cannot be determined.
+func readerSize(reader io.Reader) int64 {
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		stat, err := r.Stat()
+		if err != nil || !stat.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return stat.Size() - offset
+	}
+	return -1
+}
+
+// uploadBody is the body of an upload request that is not compressed. Once
+// Close returns, the underlying reader is no longer read, so that it can be
+// safely rewound while the transport may still hold the body.
+type uploadBody struct {
+	mu     sync.Mutex
+	r      io.Reader
+	closed bool
+}
+
+func (b *uploadBody) Read(p []byte) (n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return 0, http.ErrBodyReadAfterClose
+	}
+	return b.r.Read(p)
+}
 
-	return assetVersionId, err
+func (b *uploadBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// setUploadBody sets `reader` as the body of an upload request, without
+// reading it into memory. If `compress` is true, then the body is compressed
+// with gzip.
+func setUploadBody(req *http.Request, reader io.Reader, progress func(sent int64, total int64), compress bool) error {
+	// A reader that holds its content in memory, such as a *bytes.Buffer, is
+	// read through a bytes.Reader, so that it can be rewound.
+	if _, ok := reader.(io.Seeker); !ok {
+		if b, ok := reader.(interface{ Bytes() []byte }); ok {
+			reader = bytes.NewReader(b.Bytes())
+		}
+	}
+	size := readerSize(reader)
+	if size == 0 && !compress {
+		req.Body = http.NoBody
+		req.ContentLength = 0
+		return nil
+	}
+
+	// The starting position is recorded before any body that might read the
+	// reader exists.
+	seeker, canSeek := reader.(io.Seeker)
+	var start int64
+	if canSeek {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			return err
+		}
+	}
+
+	var mu sync.Mutex
+	var last io.ReadCloser
+	body := func() io.ReadCloser {
+		var r io.Reader = reader
+		if progress != nil {
+			r = &progressReader{r: reader, total: size, progress: progress}
+		}
+		var rc io.ReadCloser
+		if compress {
+			rc = rbxweb.GzipBody(r)
+		} else {
+			rc = &uploadBody{r: r}
+		}
+		mu.Lock()
+		last = rc
+		mu.Unlock()
+		return rc
+	}
+
+	req.Body = body()
+	if compress {
+		// The compressed size is not known until it is sent.
+		req.Header.Set("Content-Encoding", "gzip")
+		req.ContentLength = -1
+	} else if size > 0 {
+		req.ContentLength = size
+	}
+	if canSeek {
+		req.GetBody = func() (io.ReadCloser, error) {
+			// The previous body must stop reading before the reader is
+			// rewound.
+			mu.Lock()
+			prev := last
+			mu.Unlock()
+			if prev != nil {
+				prev.Close()
+			}
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return body(), nil
+		}
+	}
+	return nil
 }
 
 // UploadModel uploads data from `reader` to Roblox as a Model asset. If
 // updating an existing model, `modelId` should be the id of the model. If
 // `modelId` is 0, then a new model will be uploaded. If uploading a new
-// model, `info` can be used to specify information about the model.
+// model, `opts` can be used to specify information about the model. The Type
+// and AssetId fields of `opts` are ignored. `opts` may be nil.
 //
 // This function requires the client to be logged in.
-func UploadModel(client *rbxweb.Client, reader io.Reader, modelId int64, info url.Values) (assetVersionId int64, err error) {
-	query := url.Values{
-		"assetid": {strconv.FormatInt(modelId, 10)},
-		"type":    {"Model"},
-		//	"name":          {"Unnamed Model"},
-		//	"description":   {""},
-		//	"genreTypeId":   {"1"},
-		//	"isPublic":      {"False"},
-		//	"allowComments": {"False"},
-	}
-	if info != nil {
-		for key, value := range info {
-			query[key] = value
-		}
+func UploadModel(client *rbxweb.Client, reader io.Reader, modelId int64, opts *UploadOptions) (assetVersionId int64, err error) {
+	return UploadModelContext(context.Background(), client, reader, modelId, opts)
+}
+
+// UploadModelContext is similar to UploadModel, but the request is canceled
+// when `ctx` is done.
+func UploadModelContext(ctx context.Context, client *rbxweb.Client, reader io.Reader, modelId int64, opts *UploadOptions) (assetVersionId int64, err error) {
+	var o UploadOptions
+	if opts != nil {
+		o = *opts
 	}
+	o.Type = TypeModel
+	o.AssetId = modelId
+	return UploadContext(ctx, client, reader, o)
+}
 
-	return assetVersionId, err
+// UploadModelId is similar to UploadModel, but also returns the asset id of
+// the model. When uploading a new model, the asset id is resolved from the
+// version id with GetIdFromVersion.
+//
+// This function requires the client to be logged in.
+func UploadModelId(client *rbxweb.Client, reader io.Reader, modelId int64, opts *UploadOptions) (assetId int64, assetVersionId int64, err error) {
+	return UploadModelIdContext(context.Background(), client, reader, modelId, opts)
+}
+
+// UploadModelIdContext is similar to UploadModelId, but the requests are
+// canceled when `ctx` is done.
+func UploadModelIdContext(ctx context.Context, client *rbxweb.Client, reader io.Reader, modelId int64, opts *UploadOptions) (assetId int64, assetVersionId int64, err error) {
+	if assetVersionId, err = UploadModelContext(ctx, client, reader, modelId, opts); err != nil {
+		return 0, 0, err
+	}
+	if modelId != 0 {
+		return modelId, assetVersionId, nil
+	}
+	if assetId, err = GetIdFromVersionContext(ctx, client, assetVersionId); err != nil {
+		return 0, assetVersionId, err
+	}
+	return assetId, assetVersionId, nil
 }
 
 // UploadModelFile is similar to UploadModel, but gets the data from a file
 // name.
 //
 // This function requires the client to be logged in.
-func UploadModelFile(client *rbxweb.Client, filename string, modelId int64, info url.Values) (assetVersionId int64, err error) {
+func UploadModelFile(client *rbxweb.Client, filename string, modelId int64, opts *UploadOptions) (assetVersionId int64, err error) {
+	return UploadModelFileContext(context.Background(), client, filename, modelId, opts)
+}
+
+// UploadModelFileContext is similar to UploadModelFile, but the request is
+// canceled when `ctx` is done.
+func UploadModelFileContext(ctx context.Context, client *rbxweb.Client, filename string, modelId int64, opts *UploadOptions) (assetVersionId int64, err error) {
 	var file *os.File
 	if file, err = os.Open(filename); err != nil {
 		return 0, err
 	}
 	defer file.Close()
-	return UploadModel(client, file, modelId, info)
+	return UploadModelContext(ctx, client, file, modelId, opts)
 }
 
 // UpdatePlace uploads data from `reader` to Roblox as a Place asset.
 // `placeId` must be the id of an existing place. This function cannot create
-// a new place.
+// a new place. The Type and AssetId fields of `opts` are ignored, as are the
+// fields describing the asset. `opts` may be nil.
 //
 // This function requires the client to be logged in.
-func UpdatePlace(client *rbxweb.Client, reader io.Reader, placeId int64) (err error) {
-	query := url.Values{
-		"assetid": {strconv.FormatInt(placeId, 10)},
-		"type":    {"Place"},
-	}
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(reader)
-	req, _ := http.NewRequest("POST", client.GetURL(`www`, `/Data/Upload.ashx`, query), buf)
-	req.Header.Set("User-Agent", "Roblox")
+func UpdatePlace(client *rbxweb.Client, reader io.Reader, placeId int64, opts *UploadOptions) (err error) {
+	return UpdatePlaceContext(context.Background(), client, reader, placeId, opts)
+}
 
-	resp, err := client.Do(req)
-	if err = client.AssertResp(resp, err); err != nil {
-		return err
+// UpdatePlaceContext is similar to UpdatePlace, but the request is canceled
+// when `ctx` is done.
+func UpdatePlaceContext(ctx context.Context, client *rbxweb.Client, reader io.Reader, placeId int64, opts *UploadOptions) (err error) {
+	if placeId == 0 {
+		return errors.New("invalid place id")
 	}
-	defer resp.Body.Close()
-	return nil
+	var o UploadOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.Type = TypePlace
+	o.AssetId = placeId
+	_, err = upload(ctx, client, reader, o, "Roblox")
+	return err
 }
 
 // UpdatePlaceFile is similar to UpdatePlace, but gets the data from a file name.
 //
 // This function requires the client to be logged in.
-func UpdatePlaceFile(client *rbxweb.Client, filename string, placeId int64) (err error) {
+func UpdatePlaceFile(client *rbxweb.Client, filename string, placeId int64, opts *UploadOptions) (err error) {
+	return UpdatePlaceFileContext(context.Background(), client, filename, placeId, opts)
+}
+
+// UpdatePlaceFileContext is similar to UpdatePlaceFile, but the request is
+// canceled when `ctx` is done.
+func UpdatePlaceFileContext(ctx context.Context, client *rbxweb.Client, filename string, placeId int64, opts *UploadOptions) (err error) {
 	var file *os.File
 	if file, err = os.Open(filename); err != nil {
 		return
 	}
 	defer file.Close()
-	return UpdatePlace(client, file, placeId)
+	return UpdatePlaceContext(ctx, client, file, placeId, opts)
 }
 
 // Contains information about an asset.
 type Info struct {
-	AssetId     int64
-	ProductId   int64
-	Name        string
-	Description string
-	AssetTypeId int32
-	Creator     struct {
-		Id   int32
-		Name string
-	}
+	AssetId                int64
+	ProductId              int64
+	Name                   string
+	Description            string
+	AssetTypeId            AssetType
+	Creator                rbxweb.Creator
 	Created                time.Time
 	Updated                time.Time
 	PriceInRobux           int64
@@ -248,16 +531,100 @@ type Info struct {
 
 // GetInfo returns information about an asset, given an asset id.
 func GetInfo(client *rbxweb.Client, id int64) (info Info, err error) {
+	return GetInfoContext(context.Background(), client, id)
+}
+
+// GetInfoContext is similar to GetInfo, but the request is canceled when
+// `ctx` is done.
+func GetInfoContext(ctx context.Context, client *rbxweb.Client, id int64) (info Info, err error) {
 	query := url.Values{
 		"assetId": {strconv.FormatInt(id, 10)},
 	}
-	resp, err := client.Get(client.GetURL(`api`, `/marketplace/productinfo`, query))
+	req, err := client.NewRequest(ctx, rbxweb.EndpointProductInfo, query, nil)
+	if err != nil {
+		return Info{}, err
+	}
+	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return Info{}, err
 	}
 	defer resp.Body.Close()
 
-	dec := json.NewDecoder(resp.Body)
-	dec.Decode(&info)
-	return
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return Info{}, errors.New("JSON decode failed: " + err.Error())
+	}
+	return info, nil
+}
+
+// InfoResult is the result of getting information about a single asset with
+// GetInfos. Err is the error that occurred while getting the information, in
+// which case Info is empty.
+type InfoResult struct {
+	Info Info
+	Err  error
+}
+
+// GetInfos returns information about each asset in `ids`, mapped by asset
+// id. A failure to get one asset does not prevent the others from being
+// retrieved; the error is instead set in the result for that asset. Ids that
+// appear more than once are requested only once.
+//
+// Several requests are sent at once, up to the Concurrency of the client.
+func GetInfos(client *rbxweb.Client, ids []int64) (infos map[int64]InfoResult) {
+	return GetInfosContext(context.Background(), client, ids)
+}
+
+// GetInfosContext is similar to GetInfos, but the requests are canceled when
+// `ctx` is done. Assets that have not been retrieved when `ctx` is done have
+// the error of `ctx` as their result.
+func GetInfosContext(ctx context.Context, client *rbxweb.Client, ids []int64) (infos map[int64]InfoResult) {
+	infos = make(map[int64]InfoResult, len(ids))
+	queue := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := infos[id]; ok {
+			continue
+		}
+		infos[id] = InfoResult{}
+		queue = append(queue, id)
+	}
+
+	workers := client.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(queue) {
+		workers = len(queue)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	next := make(chan int64)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range next {
+				info, err := GetInfoContext(ctx, client, id)
+				mu.Lock()
+				infos[id] = InfoResult{Info: info, Err: err}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i, id := range queue {
+		select {
+		case next <- id:
+			continue
+		case <-ctx.Done():
+		}
+		mu.Lock()
+		for _, id := range queue[i:] {
+			infos[id] = InfoResult{Err: ctx.Err()}
+		}
+		mu.Unlock()
+		break
+	}
+	close(next)
+	wg.Wait()
+	return infos
 }
@@ -0,0 +1,414 @@
+package asset_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/anaminus/rbxweb"
+	"github.com/anaminus/rbxweb/asset"
+	"github.com/anaminus/rbxweb/rbxwebtest"
+	"github.com/anaminus/rbxweb/user"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// login returns a client of `s` that is logged in as a new user.
+func login(t *testing.T, s *rbxwebtest.Server) *rbxweb.Client {
+	t.Helper()
+	s.AddUser("bob", "hunter2")
+	client := s.Client()
+	if err := client.Login("bob", "hunter2"); err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	return client
+}
+
+// upload uploads `reader` as a new model, and returns the content of the
+// resulting asset.
+func upload(t *testing.T, client *rbxweb.Client, reader io.Reader, opts asset.UploadOptions) string {
+	t.Helper()
+	opts.Type = asset.TypeModel
+	versionId, err := asset.Upload(client, reader, opts)
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	content, err := asset.DownloadVersion(client, versionId)
+	return readContent(t, content, err)
+}
+
+// readContent reads and closes `content`.
+func readContent(t *testing.T, content *asset.Content, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	defer content.Close()
+	b, err := io.ReadAll(content)
+	if err != nil {
+		t.Fatalf("reading content failed: %v", err)
+	}
+	return string(b)
+}
+
+func TestDownload(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	u := s.AddUser("bob", "hunter2")
+	a := s.AddAsset(u.Id, 10, "Thing", []byte("content"))
+	client := s.Client()
+
+	content, err := asset.Download(client, a.Id)
+	if b := readContent(t, content, err); b != "content" {
+		t.Errorf("unexpected content %q", b)
+	}
+	if content.Length != 7 || content.Type != "application/octet-stream" {
+		t.Errorf("unexpected content metadata %+v", content)
+	}
+	if cdn := "http://" + s.CDNHost + "/cdn/"; !strings.HasPrefix(content.URL, cdn) {
+		t.Errorf("expected URL under %s, got %s", cdn, content.URL)
+	}
+
+	content, err = asset.DownloadVersion(client, a.Versions[0].Id)
+	if b := readContent(t, content, err); b != "content" {
+		t.Errorf("unexpected version content %q", b)
+	}
+
+	if _, err := asset.Download(client, 1); !rbxweb.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestGetLatestModel(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	u := s.AddUser("bob", "hunter2")
+	client := s.Client()
+
+	if _, err := asset.GetLatestModel(client, u.Id); !rbxweb.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	s.AddAsset(u.Id, 10, "First", nil)
+	a := s.AddAsset(u.Id, 10, "Second", nil)
+	if id, err := asset.GetLatestModel(client, u.Id); err != nil || id != a.Id {
+		t.Errorf("expected model %d, got %d, %v", a.Id, id, err)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	client := login(t, s)
+	id, _ := user.GetCurrentId(client)
+	a := s.AddAsset(id, 10, "Thing", []byte("first"))
+	first := a.Versions[0].Id
+
+	if _, err := asset.GetVersions(s.Client(), a.Id, 1); !rbxweb.IsUnauthorized(err) {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+
+	// Each revert adds a version, enough to fill more than one page.
+	for i := 0; i < 12; i++ {
+		if err := asset.Revert(client, first); err != nil {
+			t.Fatalf("revert %d failed: %v", i, err)
+		}
+	}
+	versions, err := asset.GetVersions(client, a.Id, 1)
+	if err != nil {
+		t.Fatalf("get versions failed: %v", err)
+	}
+	if len(versions) != 10 || versions[0].Number != 13 || versions[0].AssetId != a.Id || versions[0].CreatorId != id {
+		t.Errorf("unexpected first page %+v", versions)
+	}
+	if versions, err = asset.GetVersions(client, a.Id, 3); err != nil || len(versions) != 0 {
+		t.Errorf("expected empty page, got %d versions, %v", len(versions), err)
+	}
+
+	all, err := asset.GetAllVersions(client, a.Id)
+	if err != nil {
+		t.Fatalf("get all versions failed: %v", err)
+	}
+	if len(all) != 13 {
+		t.Fatalf("expected 13 versions, got %d", len(all))
+	}
+	for i, v := range all {
+		if v.Number != 13-i {
+			t.Errorf("version %d: expected number %d, got %d", i, 13-i, v.Number)
+		}
+	}
+	if all[12].Id != first {
+		t.Errorf("expected oldest version %d, got %d", first, all[12].Id)
+	}
+	content, err := asset.Download(client, a.Id)
+	if b := readContent(t, content, err); b != "first" {
+		t.Errorf("unexpected reverted content %q", b)
+	}
+
+	if err := asset.Revert(client, 1); !rbxweb.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestUploadModel(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	client := login(t, s)
+
+	opts := &asset.UploadOptions{Name: "Thing"}
+	versionId, err := asset.UploadModel(client, strings.NewReader("first"), 0, opts)
+	if err != nil || versionId == 0 {
+		t.Fatalf("upload failed: %d, %v", versionId, err)
+	}
+	assetId, err := asset.GetIdFromVersion(client, versionId)
+	if err != nil {
+		t.Fatalf("get id from version failed: %v", err)
+	}
+	s.Lock()
+	a := s.Assets[assetId]
+	s.Unlock()
+	if a == nil || a.Name != "Thing" || a.TypeId != 10 {
+		t.Fatalf("unexpected asset %+v", a)
+	}
+
+	// Updating a model keeps its asset id.
+	id, versionId, err := asset.UploadModelId(client, strings.NewReader("second"), assetId, nil)
+	if err != nil || id != assetId {
+		t.Fatalf("update returned asset %d, %v", id, err)
+	}
+	content, err := asset.DownloadVersion(client, versionId)
+	if b := readContent(t, content, err); b != "second" {
+		t.Errorf("unexpected updated content %q", b)
+	}
+
+	// A new model has its asset id resolved.
+	id, versionId, err = asset.UploadModelId(client, strings.NewReader("third"), 0, nil)
+	if err != nil || id == 0 || id == assetId {
+		t.Fatalf("new upload returned asset %d, %v", id, err)
+	}
+	if got, _ := asset.GetIdFromVersion(client, versionId); got != id {
+		t.Errorf("expected asset %d, got %d", id, got)
+	}
+}
+
+func TestUploadOptionsValidate(t *testing.T) {
+	valid := asset.UploadOptions{Type: asset.TypeModel, Genre: 1}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected valid options, got %v", err)
+	}
+	invalid := []asset.UploadOptions{
+		{Type: 255},
+		{Type: asset.TypeModel, AssetId: -1},
+		{Type: asset.TypeModel, Genre: 12},
+		{Type: asset.TypeModel, Genre: 16},
+	}
+	for _, opts := range invalid {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", opts)
+		}
+	}
+
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	client := login(t, s)
+	if _, err := asset.Upload(client, strings.NewReader("x"), invalid[0]); err == nil {
+		t.Error("expected upload with invalid options to fail")
+	}
+	s.Lock()
+	defer s.Unlock()
+	if len(s.Assets) != 0 {
+		t.Errorf("invalid upload reached the server")
+	}
+}
+
+func TestUploadReplay(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+
+	// Each client starts without a CSRF token, so the first upload is
+	// rejected and made again with the same data.
+	if got := upload(t, login(t, s), strings.NewReader("seeker"), asset.UploadOptions{}); got != "seeker" {
+		t.Errorf("expected %q, got %q", "seeker", got)
+	}
+	buf := bytes.NewBufferString("buffer")
+	if got := upload(t, login(t, s), buf, asset.UploadOptions{}); got != "buffer" {
+		t.Errorf("expected %q, got %q", "buffer", got)
+	}
+	if buf.Len() != len("buffer") {
+		t.Errorf("buffer was drained")
+	}
+}
+
+func TestUploadPipe(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+
+	// A pipe cannot be rewound, so a client without a CSRF token must obtain
+	// one before sending it.
+	client := login(t, s)
+	for _, compress := range []bool{false, true} {
+		client.SetCSRFToken("")
+		r, w := io.Pipe()
+		go func() {
+			io.WriteString(w, "piped")
+			w.Close()
+		}()
+		if got := upload(t, client, r, asset.UploadOptions{Compress: compress}); got != "piped" {
+			t.Errorf("expected %q, got %q", "piped", got)
+		}
+	}
+}
+
+func TestUploadProgress(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+
+	var sent, total int64
+	var calls int
+	opts := asset.UploadOptions{
+		Progress: func(s, t int64) { sent, total, calls = s, t, calls+1 },
+	}
+	data := strings.Repeat("progress ", 1000)
+	if got := upload(t, login(t, s), strings.NewReader(data), opts); got != data {
+		t.Errorf("content does not match")
+	}
+	// The count starts over when the request is replayed, so it ends at the
+	// size of the data.
+	if calls == 0 || sent != int64(len(data)) || total != int64(len(data)) {
+		t.Errorf("expected progress %d/%d, got %d/%d", len(data), len(data), sent, total)
+	}
+}
+
+func TestUploadErrors(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+
+	opts := asset.UploadOptions{Type: asset.TypeModel}
+	_, err := asset.Upload(s.Client(), strings.NewReader("content"), opts)
+	if !errors.Is(err, asset.ErrUploadUnauthorized) {
+		t.Errorf("expected ErrUploadUnauthorized, got %v", err)
+	}
+	var uerr *asset.UploadError
+	if !errors.As(err, &uerr) {
+		t.Errorf("expected *UploadError, got %T", err)
+	}
+
+	client := login(t, s)
+	s.MaxUploadSize = 4
+	_, err = asset.Upload(client, strings.NewReader("content"), opts)
+	if !errors.Is(err, asset.ErrUploadTooLarge) {
+		t.Errorf("expected ErrUploadTooLarge, got %v", err)
+	}
+
+	opts.Genre = 255
+	if _, err = asset.Upload(client, strings.NewReader("x"), opts); err == nil {
+		t.Error("expected invalid genre to fail")
+	}
+}
+
+func TestUploadGzip(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	s.Gzip = true
+
+	var sent, total int64
+	opts := asset.UploadOptions{
+		Compress: true,
+		Progress: func(s, t int64) { sent, total = s, t },
+	}
+	data := strings.Repeat("compressed ", 1000)
+	if got := upload(t, login(t, s), strings.NewReader(data), opts); got != data {
+		t.Errorf("content does not match")
+	}
+	if sent != int64(len(data)) || total != int64(len(data)) {
+		t.Errorf("expected progress %d/%d, got %d/%d", len(data), len(data), sent, total)
+	}
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Wait(ctx context.Context, host string) error {
+	return errors.New("limiter failed")
+}
+
+func TestUploadGzipLeak(t *testing.T) {
+	client := rbxweb.NewClient()
+	client.Limiter = failingLimiter{}
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		opts := asset.UploadOptions{Type: asset.TypeModel, Compress: true}
+		if _, err := asset.Upload(client, strings.NewReader("content"), opts); err == nil {
+			t.Fatal("expected limiter error")
+		}
+	}
+	// Allow goroutines that are exiting to finish.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("leaked %d goroutines", n-before)
+	}
+}
+
+func TestGetInfos(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	a := s.AddAsset(1, int32(asset.TypeModel), "a", nil)
+	b := s.AddAsset(1, int32(asset.TypeDecal), "b", nil)
+
+	infos := asset.GetInfos(s.Client(), []int64{a.Id, b.Id, a.Id, 999999})
+	if len(infos) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(infos))
+	}
+	if r := infos[a.Id]; r.Err != nil || r.Info.Name != "a" || r.Info.AssetTypeId != asset.TypeModel {
+		t.Errorf("unexpected result for a: %+v", r)
+	}
+	if r := infos[b.Id]; r.Err != nil || r.Info.Name != "b" || r.Info.AssetTypeId != asset.TypeDecal {
+		t.Errorf("unexpected result for b: %+v", r)
+	}
+	if r := infos[999999]; r.Err == nil {
+		t.Errorf("expected error for missing asset")
+	}
+}
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		s  string
+		t  asset.AssetType
+		ok bool
+	}{
+		{"Model", asset.TypeModel, true},
+		{"model", asset.TypeModel, true},
+		{"13", asset.TypeDecal, true},
+		{"Nothing", 0, false},
+		{"0", 0, false},
+	}
+	for _, test := range tests {
+		typ, err := asset.ParseType(test.s)
+		if (err == nil) != test.ok || typ != test.t {
+			t.Errorf("ParseType(%q): got %v, %v", test.s, typ, err)
+		}
+	}
+}
+
+func TestTypeFromExtension(t *testing.T) {
+	tests := []struct {
+		ext string
+		t   asset.AssetType
+		ok  bool
+	}{
+		{".rbxm", asset.TypeModel, true},
+		{"rbxmx", asset.TypeModel, true},
+		{"place.RBXL", asset.TypePlace, true},
+		{"image.png", asset.TypeDecal, true},
+		{".txt", 0, false},
+	}
+	for _, test := range tests {
+		typ, ok := asset.TypeFromExtension(test.ext)
+		if ok != test.ok || typ != test.t {
+			t.Errorf("TypeFromExtension(%q): got %v, %v", test.ext, typ, ok)
+		}
+	}
+}
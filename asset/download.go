@@ -0,0 +1,75 @@
+package asset
+
+import (
+	"context"
+	"github.com/anaminus/rbxweb"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// Content is the content of an asset, as returned by Download. The content
+// is streamed from the response body, which must be closed when finished.
+//
+// Length is the size of the content in bytes, or -1 if it is not known. Type
+// is the MIME type of the content. URL is the location from which the
+// content was received, which is usually a CDN host that the website
+// redirected to.
+type Content struct {
+	io.ReadCloser
+	Length int64
+	Type   string
+	URL    string
+}
+
+// Download returns the content of the latest version of an asset.
+//
+// Whether the client needs to be logged in depends on the asset.
+func Download(client *rbxweb.Client, id int64) (content *Content, err error) {
+	return DownloadContext(context.Background(), client, id)
+}
+
+// DownloadContext is similar to Download, but the request is canceled when
+// `ctx` is done. `ctx` also applies to reading the content.
+func DownloadContext(ctx context.Context, client *rbxweb.Client, id int64) (content *Content, err error) {
+	query := url.Values{
+		"id": {strconv.FormatInt(id, 10)},
+	}
+	return download(ctx, client, query)
+}
+
+// DownloadVersion returns the content of a specific version of an asset,
+// given an asset version id.
+//
+// Whether the client needs to be logged in depends on the asset.
+func DownloadVersion(client *rbxweb.Client, assetVersionId int64) (content *Content, err error) {
+	return DownloadVersionContext(context.Background(), client, assetVersionId)
+}
+
+// DownloadVersionContext is similar to DownloadVersion, but the request is
+// canceled when `ctx` is done. `ctx` also applies to reading the content.
+func DownloadVersionContext(ctx context.Context, client *rbxweb.Client, assetVersionId int64) (content *Content, err error) {
+	query := url.Values{
+		"assetversionid": {strconv.FormatInt(assetVersionId, 10)},
+	}
+	return download(ctx, client, query)
+}
+
+func download(ctx context.Context, client *rbxweb.Client, query url.Values) (content *Content, err error) {
+	req, err := client.NewRequest(ctx, rbxweb.EndpointAsset, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The website redirects to the CDN host that has the content, which is
+	// followed by the client.
+	resp, err := client.Do(req)
+	if err = client.AssertResp(resp, err); err != nil {
+		return nil, err
+	}
+	return &Content{
+		ReadCloser: resp.Body,
+		Length:     resp.ContentLength,
+		Type:       resp.Header.Get("Content-Type"),
+		URL:        resp.Request.URL.String(),
+	}, nil
+}
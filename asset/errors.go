@@ -0,0 +1,114 @@
+package asset
+
+import (
+	"errors"
+	"github.com/anaminus/rbxweb"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Reasons for a failed upload. An *UploadError matches one of these with
+// errors.Is.
+var (
+	ErrUploadUnauthorized = errors.New("not authorized to upload asset")
+	ErrUploadTooLarge     = errors.New("asset is too large")
+	ErrUploadModerated    = errors.New("asset was rejected by moderation")
+	ErrUploadRateLimited  = errors.New("too many uploads")
+	ErrUploadFailed       = errors.New("upload failed")
+)
+
+// The maximum length of the message kept in an UploadError.
+const maxUploadMessage = 256
+
+// UploadError is returned when the website does not accept an upload.
+//
+// Reason is one of the ErrUpload errors, which describes why the upload
+// failed. StatusCode is the status code of the response, and Message is the
+// beginning of the response body. Cause is the error returned by the client,
+// if the upload failed because of the response status, and is nil otherwise.
+type UploadError struct {
+	Reason     error
+	StatusCode int
+	Message    string
+	Cause      error
+}
+
+func (e *UploadError) Error() string {
+	s := e.Reason.Error()
+	if e.StatusCode != 0 {
+		s = s + " (" + strconv.Itoa(e.StatusCode) + ")"
+	}
+	if e.Message != "" {
+		s = s + ": \"" + e.Message + "\""
+	}
+	return s
+}
+
+// Is reports whether the reason of the error matches `target`.
+func (e *UploadError) Is(target error) bool {
+	return e.Reason == target
+}
+
+// Unwrap returns the cause of the error.
+func (e *UploadError) Unwrap() error {
+	return e.Cause
+}
+
+// Phrases that indicate the reason for a failed upload, checked in order.
+var uploadReasons = []struct {
+	reason  error
+	phrases []string
+}{
+	{ErrUploadRateLimited, []string{"too many", "rate limit", "try again later", "flood"}},
+	{ErrUploadTooLarge, []string{"too large", "too big", "file size", "exceeds"}},
+	{ErrUploadModerated, []string{"moderat", "inappropriate", "rejected"}},
+	{ErrUploadUnauthorized, []string{"not authorized", "unauthorized", "permission", "log in", "login"}},
+}
+
+// newUploadError creates an UploadError from a failed response, classifying
+// it by its status code and message.
+func newUploadError(statusCode int, body string, cause error) *UploadError {
+	message := strings.TrimSpace(body)
+	if len(message) > maxUploadMessage {
+		message = message[:maxUploadMessage]
+	}
+	e := &UploadError{
+		Reason:     ErrUploadFailed,
+		StatusCode: statusCode,
+		Message:    message,
+		Cause:      cause,
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		e.Reason = ErrUploadUnauthorized
+		return e
+	case http.StatusRequestEntityTooLarge:
+		e.Reason = ErrUploadTooLarge
+		return e
+	case http.StatusTooManyRequests:
+		e.Reason = ErrUploadRateLimited
+		return e
+	}
+	lower := strings.ToLower(message)
+	for _, r := range uploadReasons {
+		for _, phrase := range r.phrases {
+			if strings.Contains(lower, phrase) {
+				e.Reason = r.reason
+				return e
+			}
+		}
+	}
+	return e
+}
+
+// uploadStatusError converts an error returned by AssertResp for an upload
+// into an UploadError. Other errors are returned unchanged.
+func uploadStatusError(err error) error {
+	var rerr *rbxweb.Error
+	if errors.As(err, &rerr) {
+		return newUploadError(rerr.StatusCode, rerr.Body, err)
+	}
+	return err
+}
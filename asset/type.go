@@ -0,0 +1,160 @@
+package asset
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// AssetType is the type of an asset.
+type AssetType byte
+
+// Asset types.
+const (
+	TypeImage        AssetType = 1
+	TypeTShirt       AssetType = 2
+	TypeAudio        AssetType = 3
+	TypeMesh         AssetType = 4
+	TypeLua          AssetType = 5
+	TypeHTML         AssetType = 6
+	TypeText         AssetType = 7
+	TypeHat          AssetType = 8
+	TypePlace        AssetType = 9
+	TypeModel        AssetType = 10
+	TypeShirt        AssetType = 11
+	TypePants        AssetType = 12
+	TypeDecal        AssetType = 13
+	TypeAvatar       AssetType = 16
+	TypeHead         AssetType = 17
+	TypeFace         AssetType = 18
+	TypeGear         AssetType = 19
+	TypeBadge        AssetType = 21
+	TypeGroupEmblem  AssetType = 22
+	TypeAnimation    AssetType = 24
+	TypeArms         AssetType = 25
+	TypeLegs         AssetType = 26
+	TypeTorso        AssetType = 27
+	TypeRightArm     AssetType = 28
+	TypeLeftArm      AssetType = 29
+	TypeLeftLeg      AssetType = 30
+	TypeRightLeg     AssetType = 31
+	TypePackage      AssetType = 32
+	TypeYouTubeVideo AssetType = 33
+	TypeGamePass     AssetType = 34
+	TypeApp          AssetType = 35
+	TypeCode         AssetType = 37
+	TypePlugin       AssetType = 38
+)
+
+// typeInfo describes an asset type. `name` is the name used by the upload
+// service. `exts` are the file extensions of content of the type, the first
+// being the most common. `uploadable` is whether users without special
+// permissions may upload assets of the type.
+type typeInfo struct {
+	name       string
+	exts       []string
+	uploadable bool
+}
+
+var types = map[AssetType]typeInfo{
+	TypeImage:        {"Image", nil, false},
+	TypeTShirt:       {"TShirt", nil, true},
+	TypeAudio:        {"Audio", []string{".mp3", ".ogg", ".wav"}, true},
+	TypeMesh:         {"Mesh", []string{".mesh"}, false},
+	TypeLua:          {"Lua", []string{".lua"}, false},
+	TypeHTML:         {"HTML", nil, false},
+	TypeText:         {"Text", nil, false},
+	TypeHat:          {"Hat", nil, false},
+	TypePlace:        {"Place", []string{".rbxl", ".rbxlx"}, true},
+	TypeModel:        {"Model", []string{".rbxm", ".rbxmx"}, true},
+	TypeShirt:        {"Shirt", nil, true},
+	TypePants:        {"Pants", nil, true},
+	TypeDecal:        {"Decal", []string{".png", ".jpg", ".jpeg", ".bmp", ".tga"}, true},
+	TypeAvatar:       {"Avatar", nil, false},
+	TypeHead:         {"Head", nil, false},
+	TypeFace:         {"Face", nil, false},
+	TypeGear:         {"Gear", nil, false},
+	TypeBadge:        {"Badge", nil, false},
+	TypeGroupEmblem:  {"GroupEmblem", nil, false},
+	TypeAnimation:    {"Animation", nil, true},
+	TypeArms:         {"Arms", nil, false},
+	TypeLegs:         {"Legs", nil, false},
+	TypeTorso:        {"Torso", nil, false},
+	TypeRightArm:     {"RightArm", nil, false},
+	TypeLeftArm:      {"LeftArm", nil, false},
+	TypeLeftLeg:      {"LeftLeg", nil, false},
+	TypeRightLeg:     {"RightLeg", nil, false},
+	TypePackage:      {"Package", nil, false},
+	TypeYouTubeVideo: {"YouTubeVideo", nil, false},
+	TypeGamePass:     {"GamePass", nil, false},
+	TypeApp:          {"App", nil, false},
+	TypeCode:         {"Code", nil, false},
+	TypePlugin:       {"Plugin", nil, true},
+}
+
+// String returns the name of the type, as used by the upload service. An
+// unknown type is formatted as "AssetType(n)".
+func (t AssetType) String() string {
+	if info, ok := types[t]; ok {
+		return info.name
+	}
+	return "AssetType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Valid returns whether the type is a known asset type.
+func (t AssetType) Valid() bool {
+	_, ok := types[t]
+	return ok
+}
+
+// Uploadable returns whether assets of the type may be uploaded by users
+// without special permissions.
+func (t AssetType) Uploadable() bool {
+	return types[t].uploadable
+}
+
+// Extensions returns the file extensions of content of the type, including
+// the leading dot. The first extension is the most common. The result is
+// empty if the type has no associated files.
+func (t AssetType) Extensions() []string {
+	return append([]string(nil), types[t].exts...)
+}
+
+// ParseType returns the asset type matching `s`, which is either the name of
+// the type, compared case-insensitively, or its number.
+func ParseType(s string) (t AssetType, err error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		if t = AssetType(n); n > 0 && n < 256 && t.Valid() {
+			return t, nil
+		}
+		return 0, errors.New("unknown asset type " + s)
+	}
+	for t, info := range types {
+		if strings.EqualFold(info.name, s) {
+			return t, nil
+		}
+	}
+	return 0, errors.New("unknown asset type \"" + s + "\"")
+}
+
+// TypeFromExtension returns the asset type of content with the file
+// extension `ext`, such as ".rbxm". `ext` may also be a file name, and the
+// leading dot may be omitted. `ok` is false if the extension does not
+// indicate a type.
+func TypeFromExtension(ext string) (t AssetType, ok bool) {
+	if i := strings.LastIndexByte(ext, '.'); i >= 0 {
+		ext = ext[i:]
+	} else {
+		ext = "." + ext
+	}
+	ext = strings.ToLower(ext)
+	for t, info := range types {
+		for _, e := range info.exts {
+			if e == ext {
+				return t, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,134 @@
+package asset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/anaminus/rbxweb"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version contains information about a single version of an asset.
+//
+// Id is the asset version id, which can be passed to DownloadVersion to get
+// the content of the version, or to Revert to restore it. Number is the
+// position of the version, starting at 1 for the first version.
+type Version struct {
+	Id          int64
+	AssetId     int64
+	Number      int
+	Created     time.Time
+	CreatorId   int32
+	CreatorType string
+}
+
+// GetVersions returns a page of versions of an asset, ordered from newest to
+// oldest. Pages start at 1. An empty result indicates that there are no more
+// pages.
+//
+// This function requires the client to be logged in as a user that can edit
+// the asset.
+func GetVersions(client *rbxweb.Client, assetId int64, page int) (versions []Version, err error) {
+	return GetVersionsContext(context.Background(), client, assetId, page)
+}
+
+// GetVersionsContext is similar to GetVersions, but the request is canceled
+// when `ctx` is done.
+func GetVersionsContext(ctx context.Context, client *rbxweb.Client, assetId int64, page int) (versions []Version, err error) {
+	if page < 1 {
+		page = 1
+	}
+	query := url.Values{
+		"page": {strconv.Itoa(page)},
+	}
+	req, err := client.NewRequest(ctx, rbxweb.EndpointAssetVersions, query, nil, assetId)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err = client.AssertResp(resp, err); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var data []struct {
+		Id              int64
+		AssetId         int64
+		VersionNumber   int
+		CreatorType     string
+		CreatorTargetId int32
+		Created         time.Time
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, errors.New("JSON decode failed: " + err.Error())
+	}
+	versions = make([]Version, len(data))
+	for i, v := range data {
+		versions[i] = Version{
+			Id:          v.Id,
+			AssetId:     v.AssetId,
+			Number:      v.VersionNumber,
+			Created:     v.Created,
+			CreatorId:   v.CreatorTargetId,
+			CreatorType: v.CreatorType,
+		}
+	}
+	return versions, nil
+}
+
+// GetAllVersions is similar to GetVersions, but issues requests until every
+// version of the asset is returned.
+//
+// This function requires the client to be logged in as a user that can edit
+// the asset.
+func GetAllVersions(client *rbxweb.Client, assetId int64) (versions []Version, err error) {
+	return GetAllVersionsContext(context.Background(), client, assetId)
+}
+
+// GetAllVersionsContext is similar to GetAllVersions, but the requests are
+// canceled when `ctx` is done.
+func GetAllVersionsContext(ctx context.Context, client *rbxweb.Client, assetId int64) (versions []Version, err error) {
+	for page := 1; ; page++ {
+		vs, err := GetVersionsContext(ctx, client, assetId, page)
+		if err != nil {
+			return nil, err
+		}
+		if len(vs) == 0 {
+			break
+		}
+		versions = append(versions, vs...)
+	}
+	return versions, nil
+}
+
+// Revert restores an asset, such as a place or model, to an older version,
+// given the asset version id of that version. The content of the version
+// becomes the content of a new, latest version of the asset.
+//
+// This function requires the client to be logged in as a user that can edit
+// the asset.
+func Revert(client *rbxweb.Client, assetVersionId int64) (err error) {
+	return RevertContext(context.Background(), client, assetVersionId)
+}
+
+// RevertContext is similar to Revert, but the request is canceled when `ctx`
+// is done.
+func RevertContext(ctx context.Context, client *rbxweb.Client, assetVersionId int64) (err error) {
+	form := url.Values{
+		"assetVersionID": {strconv.FormatInt(assetVersionId, 10)},
+	}
+	req, err := client.NewRequest(ctx, rbxweb.EndpointRevertVersion, nil, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err = client.AssertResp(resp, err); err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
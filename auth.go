@@ -2,44 +2,144 @@ package rbxweb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"net/http/cookiejar"
 	"net/url"
+	"strings"
 )
 
 var ErrLoggedIn = errors.New("client is already logged in")
 
+// ErrNotLoggedIn is returned when a session is checked on a client that has
+// no security cookie.
+var ErrNotLoggedIn = errors.New("client is not logged in")
+
+// ErrSessionExpired is returned when the security cookie of a client is no
+// longer accepted by the website.
+var ErrSessionExpired = errors.New("session has expired")
+
+// The name of the cookie that holds the session of a logged in user.
+const securityCookie = ".ROBLOSECURITY"
+
+// securityURL returns the URL used to look up the security cookie.
+func (client *Client) securityURL() *url.URL {
+	u, _ := url.Parse(client.GetSecureURL(`www`, `/`, nil))
+	return u
+}
+
+// hasSecurityCookie returns whether the client's cookie jar has a security
+// cookie.
+func (client *Client) hasSecurityCookie() bool {
+	if client.Jar == nil {
+		return false
+	}
+	for _, cookie := range client.Jar.Cookies(client.securityURL()) {
+		if cookie.Name == securityCookie {
+			return true
+		}
+	}
+	return false
+}
+
+// clearSecurityCookie removes the security cookie from the client's cookie
+// jar.
+func (client *Client) clearSecurityCookie() {
+	if client.Jar == nil {
+		return
+	}
+	client.Jar.SetCookies(client.securityURL(), []*http.Cookie{
+		{Name: securityCookie, Path: `/`, MaxAge: -1},
+		{Name: securityCookie, Path: `/`, Domain: client.BaseDomain, MaxAge: -1},
+	})
+}
+
+// isLoginPage returns whether `u` is the location of the login page, which
+// the website redirects to when a request requires a valid session.
+func (client *Client) isLoginPage(u *url.URL) bool {
+	endpoint, ok := client.Endpoint(EndpointLoginPage)
+	return ok && u != nil && strings.EqualFold(u.Path, endpoint.Path)
+}
+
 // Login logs the client into a user account on the website. This is
 // neccessary for many API functions to properly execute.
+//
+// If the client already has a session, then it is checked first. ErrLoggedIn
+// is returned if the session is still valid, and an expired session is
+// discarded before logging in. If the session could not be checked, then the
+// error is returned, and the session is kept.
+//
+// If the website requires a challenge to be solved, then a *Challenge is
+// returned, unless the client has a ChallengeHandler. If the website rejects
+// the username or password, then the returned error matches
+// ErrInvalidCredentials.
 func (client *Client) Login(username string, password string) (err error) {
-	bd, err := json.Marshal(map[string]interface{}{
-		"userName":        username,
-		"password":        password,
-		"isCaptchaOn":     false,
-		"challenge":       "",
-		"captchaResponse": "",
-	})
-	if err != nil {
-		return err
-	}
+	return client.LoginContext(context.Background(), username, password)
+}
 
+// LoginContext is similar to Login, but the requests are canceled when `ctx`
+// is done.
+func (client *Client) LoginContext(ctx context.Context, username string, password string) (err error) {
 	// Ensure the client has a cookiejar
-	if client.Jar == nil {
-		client.Jar, _ = cookiejar.New(&cookiejar.Options{})
-	}
+	client.ensureJar()
 	// Check if the client is already logged in
-	domain, _ := url.Parse(client.GetURL(`www`, ``, nil))
-	cookies := client.Jar.Cookies(domain)
-	for _, cookie := range cookies {
-		if cookie.Name == ".ROBLOSECURITY" {
-			// Client is already logged in
+	if client.hasSecurityCookie() {
+		_, err := client.CheckSessionContext(ctx)
+		switch err {
+		case nil:
 			return ErrLoggedIn
+		case ErrSessionExpired:
+			client.clearSecurityCookie()
+		default:
+			return err
+		}
+	}
+
+	err = client.validateLogin(ctx, loginData{
+		UserName: username,
+		Password: password,
+	})
+	if client.ChallengeHandler == nil {
+		return err
+	}
+	// Let the handler solve challenges until one is not returned.
+	for {
+		chal, ok := err.(*Challenge)
+		if !ok {
+			return err
 		}
+		solution, herr := client.ChallengeHandler(chal)
+		if herr != nil {
+			return herr
+		}
+		err = client.ResolveChallengeContext(ctx, chal, solution)
 	}
+}
 
-	req, _ := http.NewRequest("POST", client.GetSecureURL(`www`, `/Services/Secure/LoginService.asmx/ValidateLogin`, nil), bytes.NewReader(bd))
+// loginData is the request data sent to the ValidateLogin service.
+type loginData struct {
+	UserName        string `json:"userName"`
+	Password        string `json:"password"`
+	IsCaptchaOn     bool   `json:"isCaptchaOn"`
+	Challenge       string `json:"challenge"`
+	CaptchaResponse string `json:"captchaResponse"`
+	TwoStepCode     string `json:"twoStepCode,omitempty"`
+}
+
+// validateLogin sends login data to the ValidateLogin service. A *Challenge
+// is returned if the service requires one to be solved.
+func (client *Client) validateLogin(ctx context.Context, data loginData) (err error) {
+	bd, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := client.NewRequest(ctx, EndpointLogin, nil, bytes.NewReader(bd))
+	if err != nil {
+		return err
+	}
 	req.Header.Add("Content-Type", "application/json; charset=utf-8")
 
 	resp, err := client.Do(req)
@@ -50,25 +150,124 @@ func (client *Client) Login(username string, password string) (err error) {
 
 	// Check response data
 	// {"d":{"sl_translate":"Message","IsValid":true,"Message":"","ErrorCode":""}}
-	respData := make(map[string]interface{})
+	var respData struct {
+		D *struct {
+			IsValid   bool
+			Message   string
+			ErrorCode string
+			Challenge string
+		} `json:"d"`
+	}
 	if err := json.NewDecoder(resp.Body).Decode(&respData); err != nil {
 		return errors.New("Login failed. JSON decode failed. " + err.Error())
 	}
-	d := respData["d"].(map[string]interface{})
-	if !d["IsValid"].(bool) {
-		return errors.New("Login failed. Error code " + d["ErrorCode"].(string) + ": \"" + d["Message"].(string) + "\"")
+	d := respData.D
+	if d == nil {
+		return errors.New("Login failed. Unexpected response.")
+	}
+	if !d.IsValid {
+		if typ := challengeType(d.ErrorCode); typ != 0 {
+			return &Challenge{
+				Type:    typ,
+				Id:      d.Challenge,
+				Message: d.Message,
+				login:   data,
+			}
+		}
+		return &Error{
+			Endpoint: req.URL.String(),
+			Code:     d.ErrorCode,
+			Message:  d.Message,
+		}
 	}
-	resp.Body.Close()
 	return nil
 }
 
 // Logout logs the client of out of the current user account.
 func (client *Client) Logout() (err error) {
-	req, _ := http.NewRequest("POST", client.GetSecureURL(`www`, `/authentication/logout`, nil), nil)
+	return client.LogoutContext(context.Background())
+}
+
+// LogoutContext is similar to Logout, but the request is canceled when `ctx`
+// is done.
+func (client *Client) LogoutContext(ctx context.Context) (err error) {
+	req, err := client.NewRequest(ctx, EndpointLogout, nil, nil)
+	if err != nil {
+		return err
+	}
 	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return err
 	}
 	resp.Body.Close()
+	client.SetCSRFToken(``)
 	return nil
 }
+
+// LoginCookie logs the client in using the value of an existing
+// .ROBLOSECURITY cookie, such as one copied from a browser. The cookie is not
+// checked; CheckSession can be used to verify that it is still valid.
+func (client *Client) LoginCookie(cookie string) {
+	client.ensureJar()
+	client.clearSecurityCookie()
+	client.Jar.SetCookies(client.securityURL(), []*http.Cookie{{
+		Name:     securityCookie,
+		Value:    cookie,
+		Domain:   client.BaseDomain,
+		Path:     `/`,
+		HttpOnly: true,
+	}})
+}
+
+// SessionUser describes the user that a client is logged in as.
+type SessionUser struct {
+	Id   int32
+	Name string
+}
+
+// CheckSession verifies that the client's session is still valid, and
+// returns the user that the client is logged in as. ErrNotLoggedIn is
+// returned if the client has no session, and ErrSessionExpired is returned if
+// the website no longer accepts the session, either by rejecting the request
+// with a 401 or 403 status, or by redirecting to the login page. Any other
+// unexpected response, such as a maintenance page, returns an error that is
+// not ErrSessionExpired, since it says nothing about the session.
+func (client *Client) CheckSession() (user SessionUser, err error) {
+	return client.CheckSessionContext(context.Background())
+}
+
+// CheckSessionContext is similar to CheckSession, but the request is
+// canceled when `ctx` is done.
+func (client *Client) CheckSessionContext(ctx context.Context) (user SessionUser, err error) {
+	if !client.hasSecurityCookie() {
+		return SessionUser{}, ErrNotLoggedIn
+	}
+	req, err := client.NewRequest(ctx, EndpointUserInfo, nil, nil)
+	if err != nil {
+		return SessionUser{}, err
+	}
+	resp, err := client.Do(req)
+	if err = client.AssertResp(resp, err); err != nil {
+		if IsUnauthorized(err) {
+			return SessionUser{}, ErrSessionExpired
+		}
+		return SessionUser{}, err
+	}
+	defer resp.Body.Close()
+
+	// An invalid session is redirected to the login page.
+	if client.isLoginPage(resp.Request.URL) {
+		return SessionUser{}, ErrSessionExpired
+	}
+	var info struct {
+		UserID   int32
+		UserName string
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return SessionUser{}, fmt.Errorf("JSON decode failed: %w", err)
+	}
+	if info.UserID == 0 {
+		return SessionUser{}, errors.New("session check failed: response has no user")
+	}
+	return SessionUser{Id: info.UserID, Name: info.UserName}, nil
+}
@@ -0,0 +1,103 @@
+package rbxweb_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/anaminus/rbxweb"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// newAuthClient returns a client of a server that accepts the session
+// "valid", and logs in any user with that session. The session "redirect" is
+// redirected to the login page, and "maintenance" gets a maintenance page.
+func newAuthClient(t *testing.T) *rbxweb.Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Services/Secure/LoginService.asmx/ValidateLogin":
+			http.SetCookie(w, &http.Cookie{Name: ".ROBLOSECURITY", Value: "valid", Domain: "roblox.com", Path: "/"})
+			io.WriteString(w, `{"d":{"IsValid":true,"Message":"","ErrorCode":""}}`)
+		case "/MobileAPI/UserInfo":
+			c, err := r.Cookie(".ROBLOSECURITY")
+			switch {
+			case err != nil:
+				w.WriteHeader(http.StatusUnauthorized)
+			case c.Value == "valid":
+				io.WriteString(w, `{"UserID":1,"UserName":"bob"}`)
+			case c.Value == "redirect":
+				http.Redirect(w, r, "/newlogin?ReturnUrl=%2FMobileAPI%2FUserInfo", http.StatusFound)
+			case c.Value == "maintenance":
+				io.WriteString(w, "<!DOCTYPE html><html><body>Down for maintenance</body></html>")
+			default:
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "/newlogin":
+			io.WriteString(w, "<!DOCTYPE html><html><body>Log in</body></html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestCheckSession(t *testing.T) {
+	client := newAuthClient(t)
+	if _, err := client.CheckSession(); err != rbxweb.ErrNotLoggedIn {
+		t.Errorf("expected ErrNotLoggedIn, got %v", err)
+	}
+
+	client.LoginCookie("valid")
+	user, err := client.CheckSession()
+	if err != nil {
+		t.Fatalf("check session failed: %v", err)
+	}
+	if user.Id != 1 || user.Name != "bob" {
+		t.Errorf("unexpected user %+v", user)
+	}
+
+	client.LoginCookie("expired")
+	if _, err := client.CheckSession(); !rbxweb.IsSessionExpired(err) {
+		t.Errorf("expected expired session, got %v", err)
+	}
+	client.LoginCookie("redirect")
+	if _, err := client.CheckSession(); !rbxweb.IsSessionExpired(err) {
+		t.Errorf("expected redirect to login page to expire session, got %v", err)
+	}
+
+	// A response that is not understood says nothing about the session.
+	client.LoginCookie("maintenance")
+	_, err = client.CheckSession()
+	var serr *json.SyntaxError
+	if rbxweb.IsSessionExpired(err) || !errors.As(err, &serr) {
+		t.Errorf("expected decode error, got %v", err)
+	}
+}
+
+func TestLoginExistingSession(t *testing.T) {
+	client := newAuthClient(t)
+	client.LoginCookie("valid")
+	if err := client.Login("bob", "hunter2"); err != rbxweb.ErrLoggedIn {
+		t.Errorf("expected ErrLoggedIn, got %v", err)
+	}
+
+	// An expired session is replaced by logging in again.
+	client.LoginCookie("expired")
+	if err := client.Login("bob", "hunter2"); err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	if _, err := client.CheckSession(); err != nil {
+		t.Errorf("expected new session to be valid, got %v", err)
+	}
+}
+
+func TestLoginKeepsSessionOnMaintenance(t *testing.T) {
+	client := newAuthClient(t)
+	client.LoginCookie("maintenance")
+	if err := client.Login("bob", "hunter2"); err == nil || err == rbxweb.ErrLoggedIn {
+		t.Fatalf("expected session check to fail, got %v", err)
+	}
+	// The session was not discarded.
+	if _, err := client.CheckSession(); err == rbxweb.ErrNotLoggedIn {
+		t.Error("session cookie was removed")
+	}
+}
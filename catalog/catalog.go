@@ -2,9 +2,14 @@
 package catalog
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/anaminus/rbxweb"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Used with the Category field of a Query.
@@ -112,7 +117,8 @@ const (
 // search.
 const MaxResults = 42
 
-// Query is used with Search and SearchAll to query assets.
+// Query is used with Search and SearchAll to query assets. A Query may be
+// constructed directly, or with NewQuery.
 type Query struct {
 	Gears                []byte
 	Genres               []byte
@@ -133,7 +139,45 @@ type Query struct {
 
 // Result represents a single asset returned from a call to Search or
 // SearchAll.
+//
+// Numbers formatted by the website, such as "1,234", are parsed into numeric
+// fields. PriceInRobux, PriceInTickets, and BestPrice are 0 if the asset is
+// free, and -1 if the asset has no price in that currency. Remaining is -1 if
+// the asset is not limited. A value that cannot be parsed is treated as
+// missing. Updated is a description of when the asset was updated, such as
+// "2 days ago", while CreatedDate and UpdatedDate are the exact times.
+//
+// Raw contains the result as it was returned by the website.
 type Result struct {
+	AssetId                int64
+	Name                   string
+	Url                    string
+	PriceInRobux           int64
+	PriceInTickets         int64
+	Updated                string
+	Favorited              int64
+	Sales                  int64
+	Remaining              int64
+	Creator                rbxweb.Creator
+	CreatorUrl             string
+	PrivateSales           int64
+	PriceView              int32
+	BestPrice              int64
+	ContentRatingTypeID    int32
+	AssetTypeID            int32
+	CreatedDate            time.Time
+	UpdatedDate            time.Time
+	IsForSale              bool
+	IsPublicDomain         bool
+	IsLimited              bool
+	IsLimitedUnique        bool
+	MinimumMembershipLevel int32
+	Raw                    RawResult
+}
+
+// RawResult is a result as it is returned by the catalog search, with
+// numbers and dates formatted as strings.
+type RawResult struct {
 	AssetId                int64
 	Name                   string
 	Url                    string
@@ -160,105 +204,173 @@ type Result struct {
 	MinimumMembershipLevel int32
 }
 
-// Converts a Query to URL values.
-func convertQuery(query Query) (values url.Values) {
-	values = url.Values{}
-
-	if v, ok := query["Gears"].([]byte); ok {
-		for _, b := range v {
-			values.Add(k, strconv.Itoa(int(b)))
-		}
-	}
-	if v, ok := query["Genres"].([]byte); ok {
-		for _, b := range v {
-			values.Add(k, strconv.Itoa(int(b)))
-		}
-	}
-	if v, ok := query["Subcategory"].(byte); ok {
-		values.Set(k, strconv.Itoa(int(v)))
+// Result converts the raw result into a Result.
+func (r RawResult) Result() Result {
+	created, _ := ParseDate(r.CreatedDate)
+	updated, _ := ParseDate(r.UpdatedDate)
+	return Result{
+		AssetId:                r.AssetId,
+		Name:                   r.Name,
+		Url:                    r.Url,
+		PriceInRobux:           parsePrice(r.PriceInRobux),
+		PriceInTickets:         parsePrice(r.PriceInTickets),
+		Updated:                r.Updated,
+		Favorited:              parseCount(r.Favorited, 0),
+		Sales:                  parseCount(r.Sales, 0),
+		Remaining:              parseCount(r.Remaining, -1),
+		Creator:                rbxweb.Creator{Id: r.CreatorID, Name: r.Creator},
+		CreatorUrl:             r.CreatorUrl,
+		PrivateSales:           parseCount(r.PrivateSales, 0),
+		PriceView:              r.PriceView,
+		BestPrice:              parsePrice(r.BestPrice),
+		ContentRatingTypeID:    r.ContentRatingTypeID,
+		AssetTypeID:            r.AssetTypeID,
+		CreatedDate:            created,
+		UpdatedDate:            updated,
+		IsForSale:              r.IsForSale,
+		IsPublicDomain:         r.IsPublicDomain,
+		IsLimited:              r.IsLimited,
+		IsLimitedUnique:        r.IsLimitedUnique,
+		MinimumMembershipLevel: r.MinimumMembershipLevel,
+		Raw:                    r,
 	}
-	if v, ok := query["Category"].(byte); ok {
-		values.Set(k, strconv.Itoa(int(v)))
-	}
-	if v, ok := query["CurrencyType"].(byte); ok {
-		values.Set(k, strconv.Itoa(int(v)))
-	}
-	if v, ok := query["SortType"].(byte); ok {
-		values.Set(k, strconv.Itoa(int(v)))
-	}
-	if v, ok := query["AggregationFrequency"].(byte); ok {
-		values.Set(k, strconv.Itoa(int(v)))
+}
+
+// parseCount parses a number formatted by the website, such as "1,234".
+// `missing` is returned if the number is empty or cannot be parsed.
+func parseCount(s string, missing int64) int64 {
+	s = strings.Replace(strings.TrimSpace(s), ",", "", -1)
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return missing
 	}
-	if v, ok := query["SortCurrency"].(byte); ok {
-		values.Set(k, strconv.Itoa(int(v)))
+	return n
+}
+
+// parsePrice parses a price formatted by the website. A free price is 0, and
+// a missing price, such as "--", is -1.
+func parsePrice(s string) int64 {
+	if strings.EqualFold(strings.TrimSpace(s), "Free") {
+		return 0
 	}
-	if v, ok := query["Keyword"].(string); ok {
-		values.Set(k, v)
+	return parseCount(s, -1)
+}
+
+// ParseDate parses a date returned by the website, which is either of the
+// form "/Date(1234567890123)/", in milliseconds since the Unix epoch, or in
+// RFC 3339 format. An empty string results in the zero time.
+func ParseDate(s string) (t time.Time, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
 	}
-	if v, ok := query["CreatorID"].(int); ok {
-		values.Set(k, strconv.Itoa(v))
+	if strings.HasPrefix(s, "/Date(") && strings.HasSuffix(s, ")/") {
+		ms := s[len("/Date(") : len(s)-len(")/")]
+		// The time may be followed by a time zone offset, such as "-0500",
+		// which does not affect the instant.
+		if i := strings.LastIndexAny(ms, "+-"); i > 0 {
+			ms = ms[:i]
+		}
+		n, err := strconv.ParseInt(ms, 10, 64)
+		if err != nil {
+			return time.Time{}, errors.New("invalid date " + s)
+		}
+		return time.Unix(0, n*int64(time.Millisecond)), nil
 	}
-	if v, ok := query["PxMin"].(int); ok {
-		values.Set(k, strconv.Itoa(v))
+	return time.Parse(time.RFC3339, s)
+}
+
+// Converts a Query to URL values. Fields with zero values are omitted, which
+// causes the website to use its defaults for them.
+func convertQuery(query Query) (values url.Values) {
+	values = url.Values{}
+	setInt := func(k string, v int) {
+		if v != 0 {
+			values.Set(k, strconv.Itoa(v))
+		}
 	}
-	if v, ok := query["PxMax"].(int); ok {
-		values.Set(k, strconv.Itoa(v))
+
+	for _, v := range query.Gears {
+		values.Add("Gears", strconv.Itoa(int(v)))
 	}
-	if v, ok := query["IncludeNotForSale"].(bool); ok {
-		values.Set(k, strconv.FormatBool(v))
+	for _, v := range query.Genres {
+		values.Add("Genres", strconv.Itoa(int(v)))
 	}
-	if v, ok := query["PageNumber"].(int); ok {
-		values.Set(k, strconv.Itoa(v))
+	setInt("Subcategory", int(query.Subcategory))
+	setInt("Category", int(query.Category))
+	setInt("CurrencyType", int(query.CurrencyType))
+	setInt("SortType", int(query.SortType))
+	setInt("AggregationFrequency", int(query.AggregationFrequency))
+	setInt("SortCurrency", int(query.SortCurrency))
+	if query.Keyword != "" {
+		values.Set("Keyword", query.Keyword)
 	}
-	if v, ok := query["ResultsPerPage"].(int); ok {
-		values.Set(k, strconv.Itoa(v))
+	setInt("CreatorID", query.CreatorID)
+	setInt("PxMin", query.PxMin)
+	setInt("PxMax", query.PxMax)
+	if query.IncludeNotForSale {
+		values.Set("IncludeNotForSale", "true")
 	}
+	setInt("PageNumber", query.PageNumber)
+	setInt("ResultsPerPage", query.ResultsPerPage)
 	return
 }
 
-// Search is used to perform a search query for Roblox assets.
+// Search is used to perform a search query for Roblox assets. An error is
+// returned without sending a request if the query is not valid.
 func Search(client *rbxweb.Client, query Query) (result []Result, err error) {
+	return SearchContext(context.Background(), client, query)
+}
+
+// SearchContext is similar to Search, but the request is canceled when `ctx`
+// is done.
+func SearchContext(ctx context.Context, client *rbxweb.Client, query Query) (result []Result, err error) {
+	if err = query.Validate(); err != nil {
+		return nil, err
+	}
 	values := convertQuery(query)
-	resp, err := client.Get(client.GetURL(`www`, `/catalog/json`, values))
+	req, err := client.NewRequest(ctx, rbxweb.EndpointCatalogSearch, values, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	dec := json.NewDecoder(resp.Body)
-	dec.Decode(&result)
-	return
+	var raw []RawResult
+	if err = json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, errors.New("JSON decode failed: " + err.Error())
+	}
+	result = make([]Result, len(raw))
+	for i, r := range raw {
+		result[i] = r.Result()
+	}
+	return result, nil
 }
 
 // SearchAll is similar to Search, but issues multiple requests until n
 // results are found. If n is less than 0, then every found result will be
 // returned. If PageNumber is specified in query, then requests will start
-// from that page.
+// from that page. Requests stop when a page with no results is returned.
 func SearchAll(client *rbxweb.Client, n int, query Query) (result []Result, err error) {
+	return SearchAllContext(context.Background(), client, n, query)
+}
+
+// SearchAllContext is similar to SearchAll, but the requests are canceled
+// when `ctx` is done.
+func SearchAllContext(ctx context.Context, client *rbxweb.Client, n int, query Query) (result []Result, err error) {
 	if n == 0 {
 		return
 	}
 
-	i := 0
-	p := query.PageNumber
-loop:
-	for {
-		query.Set("PageNumber", p)
-		rs, err := Catalog(client, query)
-		if err != nil {
-			return nil, err
-		}
-		if len(rs) == 0 {
-			break loop
-		}
-		for _, r := range rs {
-			result = append(result, r...)
-			i = i + 1
-			if n > 0 && i >= n {
-				break loop
-			}
-		}
-		p = p + 1
+	it := NewIteratorContext(ctx, client, query)
+	for (n < 0 || len(result) < n) && it.Next() {
+		result = append(result, it.Result())
 	}
-	return
+	if err = it.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
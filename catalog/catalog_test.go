@@ -0,0 +1,248 @@
+package catalog
+
+import (
+	"context"
+	"github.com/anaminus/rbxweb/rbxwebtest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestConvertQuery(t *testing.T) {
+	if values := convertQuery(Query{}); len(values) != 0 {
+		t.Errorf("expected empty query to have no values, got %v", values)
+	}
+	values := convertQuery(Query{
+		Category:          CatGear,
+		Gears:             []byte{GearMeleeWeapon, GearRangedWeapon},
+		Keyword:           "sword",
+		CreatorID:         1,
+		IncludeNotForSale: true,
+		PageNumber:        2,
+	})
+	expected := url.Values{
+		"Category":          {"5"},
+		"Gears":             {"1", "2"},
+		"Keyword":           {"sword"},
+		"CreatorID":         {"1"},
+		"IncludeNotForSale": {"true"},
+		"PageNumber":        {"2"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		s   string
+		t   time.Time
+		err bool
+	}{
+		{"", time.Time{}, false},
+		{"/Date(1500)/", time.Unix(1, 500*int64(time.Millisecond)), false},
+		{"/Date(1500-0500)/", time.Unix(1, 500*int64(time.Millisecond)), false},
+		{"/Date(-1500)/", time.Unix(-2, 500*int64(time.Millisecond)), false},
+		{"2017-01-02T03:04:05Z", time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"/Date()/", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, test := range tests {
+		d, err := ParseDate(test.s)
+		if (err != nil) != test.err || !d.Equal(test.t) {
+			t.Errorf("ParseDate(%q): got %v, %v", test.s, d, err)
+		}
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		s string
+		n int64
+	}{
+		{"Free", 0},
+		{"free", 0},
+		{"--", -1},
+		{"", -1},
+		{"15", 15},
+		{"1,234", 1234},
+	}
+	for _, test := range tests {
+		if n := parsePrice(test.s); n != test.n {
+			t.Errorf("parsePrice(%q): expected %d, got %d", test.s, test.n, n)
+		}
+	}
+	if n := parseCount("", 0); n != 0 {
+		t.Errorf("expected missing count to be 0, got %d", n)
+	}
+	if n := parseCount("12,345,678", 0); n != 12345678 {
+		t.Errorf("expected 12345678, got %d", n)
+	}
+}
+
+func TestValidGenre(t *testing.T) {
+	for _, g := range []byte{GenreTownandCity, GenreMilitary, GenreBuilding, GenreRPG} {
+		if !ValidGenre(g) {
+			t.Errorf("expected genre %d to be valid", g)
+		}
+	}
+	for _, g := range []byte{0, 12, 16, 255} {
+		if ValidGenre(g) {
+			t.Errorf("expected genre %d to be invalid", g)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []Query{
+		{Category: CatAudio + 1},
+		{SortType: SortPriceHighToLow + 1},
+		{Gears: []byte{GearMeleeWeapon}},
+		{Category: CatGear, Gears: []byte{0}},
+		{Genres: []byte{255}},
+		{CreatorID: -1},
+		{PxMin: -1},
+		{PxMin: 10, PxMax: 5},
+		{PageNumber: -1},
+		{ResultsPerPage: -1},
+		{ResultsPerPage: MaxResults + 1},
+		{Category: CatDecals, Subcategory: SubcatAudio},
+		{Category: CatPlugins, Subcategory: SubcatAll},
+		{SortType: SortRelevance, AggregationFrequency: AggrAllTime},
+		{SortType: SortBestselling, SortCurrency: SortCurrencyTickets},
+	}
+	for _, query := range invalid {
+		if err := query.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", query)
+		}
+	}
+	valid := []Query{
+		{},
+		{Category: CatGear, Gears: []byte{GearMeleeWeapon}},
+		{Genres: []byte{GenreFPS, GenreRPG}},
+		{PxMin: 10},
+		{ResultsPerPage: MaxResults},
+		{Category: CatAudio, Subcategory: SubcatAudio},
+		{Category: CatPlugins, Subcategory: SubcatFeatured},
+		{SortType: SortBestselling, AggregationFrequency: AggrPastWeek},
+		{SortType: SortPriceLowToHigh, SortCurrency: SortCurrencyTickets},
+	}
+	for _, query := range valid {
+		if err := query.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid: %v", query, err)
+		}
+	}
+}
+
+func TestQueryBuilder(t *testing.T) {
+	query, err := NewQuery().
+		Category(CatGear, 0).
+		Gears(GearMeleeWeapon).
+		Price(0, 100).
+		ResultsPerPage(100).
+		Build()
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if query.ResultsPerPage != MaxResults {
+		t.Errorf("expected results per page to be clamped, got %d", query.ResultsPerPage)
+	}
+	if query.PxMax != 100 || len(query.Gears) != 1 {
+		t.Errorf("unexpected query %+v", query)
+	}
+	if _, err = NewQuery().Gears(GearMeleeWeapon).Build(); err == nil {
+		t.Error("expected gears without the gear category to fail")
+	}
+}
+
+// newCatalog returns a server with `n` assets created by a user, whose id is
+// returned.
+func newCatalog(n int) (s *rbxwebtest.Server, creator int) {
+	s = rbxwebtest.NewServer()
+	u := s.AddUser("bob", "hunter2")
+	for i := 0; i < n; i++ {
+		a := s.AddAsset(u.Id, 10, "model"+strconv.Itoa(i), nil)
+		a.IsForSale = true
+	}
+	return s, int(u.Id)
+}
+
+func TestSearchAll(t *testing.T) {
+	s, creator := newCatalog(25)
+	defer s.Close()
+	client := s.Client()
+	query := Query{CreatorID: creator, ResultsPerPage: 10}
+
+	results, err := SearchAll(client, -1, query)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 25 {
+		t.Errorf("expected 25 results, got %d", len(results))
+	}
+	seen := make(map[int64]bool)
+	for _, r := range results {
+		if seen[r.AssetId] {
+			t.Errorf("duplicate result %d", r.AssetId)
+		}
+		seen[r.AssetId] = true
+		if r.Creator.Id != int32(creator) || r.Creator.Name != "bob" {
+			t.Errorf("unexpected creator %+v", r.Creator)
+		}
+		if r.PriceInRobux != 0 || r.UpdatedDate.IsZero() {
+			t.Errorf("unexpected result %+v", r)
+		}
+	}
+
+	if results, err = SearchAll(client, 5, query); err != nil || len(results) != 5 {
+		t.Errorf("expected 5 results, got %d, %v", len(results), err)
+	}
+	query.PageNumber = 3
+	if results, err = SearchAll(client, -1, query); err != nil || len(results) != 5 {
+		t.Errorf("expected 5 results from page 3, got %d, %v", len(results), err)
+	}
+	if results, err = SearchAll(client, -1, Query{PxMin: -1}); err == nil {
+		t.Error("expected invalid query to fail")
+	}
+}
+
+func TestIterator(t *testing.T) {
+	s, creator := newCatalog(5)
+	defer s.Close()
+	client := s.Client()
+
+	it := NewIterator(client, Query{CreatorID: creator, ResultsPerPage: 2})
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("expected 5 results, got %d", n)
+	}
+	// Pages 1 through 3 have results, and page 4 is empty.
+	if it.Page() != 4 {
+		t.Errorf("expected next page 4, got %d", it.Page())
+	}
+	if it.Next() {
+		t.Error("expected finished iterator to stay finished")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it = NewIteratorContext(ctx, client, Query{CreatorID: creator, ResultsPerPage: 2})
+	if !it.Next() {
+		t.Fatalf("expected a result: %v", it.Err())
+	}
+	cancel()
+	if it.Next() {
+		t.Error("expected canceled iterator to stop")
+	}
+	if it.Err() != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", it.Err())
+	}
+}
@@ -0,0 +1,102 @@
+package catalog
+
+import (
+	"context"
+	"github.com/anaminus/rbxweb"
+)
+
+// Iterator iterates over the results of a catalog search, requesting each
+// page of results only when the previous page has been consumed. Iteration
+// stops when a page with no results is returned, when an error occurs, or
+// when the context of the iterator is done.
+//
+//	it := catalog.NewIterator(client, query)
+//	for it.Next() {
+//		result := it.Result()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	ctx    context.Context
+	client *rbxweb.Client
+	query  Query
+	page   []Result
+	result Result
+	done   bool
+	err    error
+}
+
+// NewIterator returns an iterator over the results of `query`. If PageNumber
+// is specified in the query, then iteration starts from that page. If
+// ResultsPerPage is greater than MaxResults, then MaxResults is used
+// instead.
+func NewIterator(client *rbxweb.Client, query Query) *Iterator {
+	return NewIteratorContext(context.Background(), client, query)
+}
+
+// NewIteratorContext is similar to NewIterator, but iteration stops and the
+// current request is canceled when `ctx` is done.
+func NewIteratorContext(ctx context.Context, client *rbxweb.Client, query Query) *Iterator {
+	if query.PageNumber < 1 {
+		query.PageNumber = 1
+	}
+	if query.ResultsPerPage > MaxResults {
+		query.ResultsPerPage = MaxResults
+	}
+	return &Iterator{ctx: ctx, client: client, query: query}
+}
+
+// Next advances the iterator to the next result, which is then available
+// from Result. It returns false when there are no more results, or when an
+// error occurred, which is then available from Err.
+func (it *Iterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.stop(err)
+		return false
+	}
+	if len(it.page) == 0 {
+		page, err := SearchContext(it.ctx, it.client, it.query)
+		if err != nil {
+			it.stop(err)
+			return false
+		}
+		if len(page) == 0 {
+			it.stop(nil)
+			return false
+		}
+		it.page = page
+		it.query.PageNumber++
+	}
+	it.result = it.page[0]
+	it.page = it.page[1:]
+	return true
+}
+
+// stop ends the iteration with the given error.
+func (it *Iterator) stop(err error) {
+	it.done = true
+	it.err = err
+	it.page = nil
+	it.result = Result{}
+}
+
+// Result returns the current result of the iterator.
+func (it *Iterator) Result() Result {
+	return it.result
+}
+
+// Err returns the error that stopped the iterator, or nil if the iterator
+// ran out of results.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Page returns the page number of the next page that will be requested.
+func (it *Iterator) Page() int {
+	return it.query.PageNumber
+}
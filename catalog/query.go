@@ -0,0 +1,223 @@
+package catalog
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ValidGenre returns whether `genre` is one of the Genre constants.
+func ValidGenre(genre byte) bool {
+	switch genre {
+	case GenreTownandCity, GenreMedieval, GenreSciFi, GenreFighting,
+		GenreHorror, GenreNaval, GenreAdventure, GenreSports, GenreComedy,
+		GenreWestern, GenreMilitary, GenreBuilding, GenreFPS, GenreRPG:
+		return true
+	}
+	return false
+}
+
+// The subcategories that the catalog lists under each category. The zero
+// subcategory is omitted from a search, and so is valid with any category.
+var subcategories = map[byte][]byte{
+	CatFeatured:     {SubcatHats, SubcatGear, SubcatFaces, SubcatPackages},
+	CatAll:          {SubcatAll, SubcatRobloxCreated},
+	CatCollectibles: {SubcatCollectibles, SubcatHats, SubcatGear, SubcatFaces},
+	CatClothing:     {SubcatClothing, SubcatHats, SubcatShirts, SubcatTshirts, SubcatPants, SubcatPackages, SubcatRobloxCreated},
+	CatBodyParts:    {SubcatBodyParts, SubcatHeads, SubcatFaces, SubcatPackages},
+	CatGear:         {SubcatGear, SubcatRobloxCreated},
+	CatModels:       {SubcatModels, SubcatRobloxCreated},
+	CatPlugins:      {},
+	CatDecals:       {SubcatDecals},
+	CatAudio:        {SubcatAudio, SubcatRobloxCreated},
+}
+
+// validSubcategory returns whether `subcategory` may be used with
+// `category`.
+func validSubcategory(category byte, subcategory byte) bool {
+	if subcategory == SubcatFeatured {
+		return true
+	}
+	for _, s := range subcategories[category] {
+		if s == subcategory {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the query contains values that are not
+// understood by the catalog search, or that do not make sense together.
+//
+// AggregationFrequency applies only to SortMostFavorited and
+// SortBestselling, and SortCurrency applies only to sorts by price, so a
+// value other than 0 is invalid with other sort types. ResultsPerPage cannot
+// be greater than MaxResults.
+func (query Query) Validate() error {
+	checks := []struct {
+		name  string
+		value byte
+		max   byte
+	}{
+		{"category", query.Category, CatAudio},
+		{"subcategory", query.Subcategory, SubcatRobloxCreated},
+		{"currency type", query.CurrencyType, CurrencyFree},
+		{"sort type", query.SortType, SortPriceHighToLow},
+		{"aggregation frequency", query.AggregationFrequency, AggrAllTime},
+		{"sort currency", query.SortCurrency, SortCurrencyTickets},
+	}
+	for _, c := range checks {
+		if c.value > c.max {
+			return errors.New("invalid " + c.name + " " + strconv.Itoa(int(c.value)))
+		}
+	}
+	if !validSubcategory(query.Category, query.Subcategory) {
+		return errors.New("subcategory " + strconv.Itoa(int(query.Subcategory)) + " is not in category " + strconv.Itoa(int(query.Category)))
+	}
+	switch query.SortType {
+	case SortMostFavorited, SortBestselling:
+	default:
+		if query.AggregationFrequency != 0 {
+			return errors.New("aggregation frequency requires a sort by favorites or sales")
+		}
+	}
+	switch query.SortType {
+	case SortPriceLowToHigh, SortPriceHighToLow:
+	default:
+		if query.SortCurrency != 0 {
+			return errors.New("sort currency requires a sort by price")
+		}
+	}
+	if len(query.Gears) > 0 && query.Category != CatGear {
+		return errors.New("gears require the gear category")
+	}
+	for _, g := range query.Gears {
+		if g < GearMeleeWeapon || g > GearPersonalTransport {
+			return errors.New("invalid gear " + strconv.Itoa(int(g)))
+		}
+	}
+	for _, g := range query.Genres {
+		if !ValidGenre(g) {
+			return errors.New("invalid genre " + strconv.Itoa(int(g)))
+		}
+	}
+	if query.CreatorID < 0 {
+		return errors.New("invalid creator id " + strconv.Itoa(query.CreatorID))
+	}
+	if query.PxMin < 0 || query.PxMax < 0 {
+		return errors.New("price range cannot be negative")
+	}
+	if query.PxMax != 0 && query.PxMin > query.PxMax {
+		return errors.New("minimum price " + strconv.Itoa(query.PxMin) + " is greater than maximum price " + strconv.Itoa(query.PxMax))
+	}
+	if query.PageNumber < 0 {
+		return errors.New("invalid page number " + strconv.Itoa(query.PageNumber))
+	}
+	if query.ResultsPerPage < 0 || query.ResultsPerPage > MaxResults {
+		return errors.New("invalid results per page " + strconv.Itoa(query.ResultsPerPage))
+	}
+	return nil
+}
+
+// QueryBuilder builds a Query by chaining method calls:
+//
+//	query, err := catalog.NewQuery().
+//		Category(catalog.CatGear, catalog.SubcatGear).
+//		Gears(catalog.GearMeleeWeapon).
+//		Price(0, 100).
+//		Build()
+type QueryBuilder struct {
+	query Query
+}
+
+// NewQuery returns a builder for an empty Query.
+func NewQuery() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// Keyword sets the keyword to search for.
+func (b *QueryBuilder) Keyword(keyword string) *QueryBuilder {
+	b.query.Keyword = keyword
+	return b
+}
+
+// Category sets the category and subcategory to search within.
+func (b *QueryBuilder) Category(category byte, subcategory byte) *QueryBuilder {
+	b.query.Category = category
+	b.query.Subcategory = subcategory
+	return b
+}
+
+// Gears adds gear types to search for. This requires the CatGear category.
+func (b *QueryBuilder) Gears(gears ...byte) *QueryBuilder {
+	b.query.Gears = append(b.query.Gears, gears...)
+	return b
+}
+
+// Genres adds genres to search for.
+func (b *QueryBuilder) Genres(genres ...byte) *QueryBuilder {
+	b.query.Genres = append(b.query.Genres, genres...)
+	return b
+}
+
+// Creator limits the search to assets created by the given user.
+func (b *QueryBuilder) Creator(creatorId int) *QueryBuilder {
+	b.query.CreatorID = creatorId
+	return b
+}
+
+// Currency sets the currency type of the assets to search for.
+func (b *QueryBuilder) Currency(currencyType byte) *QueryBuilder {
+	b.query.CurrencyType = currencyType
+	return b
+}
+
+// Price limits the search to assets with a price between `min` and `max`,
+// inclusive. If `max` is 0, then the price has no upper limit.
+func (b *QueryBuilder) Price(min, max int) *QueryBuilder {
+	b.query.PxMin = min
+	b.query.PxMax = max
+	return b
+}
+
+// Sort sets how results are sorted. `aggregation` applies to sorts that
+// count over a period of time, such as SortBestselling, and `currency`
+// applies to sorts by price. Each must be 0 for sorts it does not apply to.
+func (b *QueryBuilder) Sort(sortType byte, aggregation byte, currency byte) *QueryBuilder {
+	b.query.SortType = sortType
+	b.query.AggregationFrequency = aggregation
+	b.query.SortCurrency = currency
+	return b
+}
+
+// IncludeNotForSale sets whether assets that are not for sale are included.
+func (b *QueryBuilder) IncludeNotForSale(include bool) *QueryBuilder {
+	b.query.IncludeNotForSale = include
+	return b
+}
+
+// Page sets the page of results to start from, starting at 1.
+func (b *QueryBuilder) Page(page int) *QueryBuilder {
+	b.query.PageNumber = page
+	return b
+}
+
+// ResultsPerPage sets the number of results returned by each request. The
+// value is clamped to MaxResults.
+func (b *QueryBuilder) ResultsPerPage(n int) *QueryBuilder {
+	if n > MaxResults {
+		n = MaxResults
+	}
+	b.query.ResultsPerPage = n
+	return b
+}
+
+// Build returns the built Query, or an error if the query is not valid.
+func (b *QueryBuilder) Build() (query Query, err error) {
+	query = b.query
+	query.Gears = append([]byte(nil), b.query.Gears...)
+	query.Genres = append([]byte(nil), b.query.Genres...)
+	if err = query.Validate(); err != nil {
+		return Query{}, err
+	}
+	return query, nil
+}
@@ -0,0 +1,302 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/anaminus/rbxweb"
+	"github.com/anaminus/rbxweb/internal/atomicfile"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of change reported by an Event.
+type EventType byte
+
+// Types of events emitted by a Watcher.
+const (
+	EventNew          EventType = 1 // The item was not seen before.
+	EventUpdated      EventType = 2 // The item was updated since it was last seen.
+	EventPriceChanged EventType = 3 // The price of the item changed.
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventNew:
+		return "new"
+	case EventUpdated:
+		return "updated"
+	case EventPriceChanged:
+		return "price changed"
+	}
+	return "unknown event"
+}
+
+// Event describes a change to an item in the catalog. Result is the item as
+// it was found. For events other than EventNew, Previous is the state of the
+// item when it was last seen.
+type Event struct {
+	Type     EventType
+	Result   Result
+	Previous ItemState
+}
+
+// ItemState is the state of an item that is tracked by a Watcher.
+type ItemState struct {
+	UpdatedDate    time.Time
+	PriceInRobux   int64
+	PriceInTickets int64
+}
+
+func itemState(r Result) ItemState {
+	return ItemState{
+		UpdatedDate:    r.UpdatedDate,
+		PriceInRobux:   r.PriceInRobux,
+		PriceInTickets: r.PriceInTickets,
+	}
+}
+
+// DefaultWatchInterval is the interval used by a Watcher with no Interval.
+const DefaultWatchInterval = time.Minute
+
+// Watcher polls the catalog for items from certain creators, and reports
+// items that are new, updated, or have changed in price.
+//
+// Creators are the ids of the users whose items are watched. Query is the
+// base of each search, whose CreatorID and SortType are replaced; the
+// remaining fields can be used to narrow the search. Interval is the time
+// between polls.
+//
+// Items are searched from most to least recently updated. The search for a
+// creator stops at the first item that has not changed since it was last
+// seen, or after MaxPages pages, if MaxPages is greater than 0. Because a
+// change in price does not change when an item was updated, a price change
+// is only detected for items that are searched. If RescanPages is greater
+// than 0, then the first RescanPages pages of each creator are always
+// searched in full, so that price changes of the items on them are detected.
+//
+// Every item found by the first poll is new. The state of the watcher can be
+// saved with SaveCheckpoint and restored with LoadCheckpoint, so that items
+// are not reported again after a restart. If CheckpointFile is not empty,
+// then Run does this automatically.
+//
+// ErrorHandler, if not nil, is called by Run with each error that occurs
+// while polling or saving the checkpoint.
+type Watcher struct {
+	Client         *rbxweb.Client
+	Creators       []int
+	Query          Query
+	Interval       time.Duration
+	MaxPages       int
+	RescanPages    int
+	CheckpointFile string
+	ErrorHandler   func(err error)
+
+	mu    sync.Mutex
+	items map[int64]ItemState
+}
+
+// NewWatcher returns a watcher for items created by `creators`. Items that
+// are not for sale are included.
+func NewWatcher(client *rbxweb.Client, creators ...int) *Watcher {
+	return &Watcher{
+		Client:   client,
+		Creators: creators,
+		Query:    Query{IncludeNotForSale: true, ResultsPerPage: MaxResults},
+		Interval: DefaultWatchInterval,
+	}
+}
+
+// Poll searches the catalog once, and returns the changes found since the
+// previous poll, from oldest to newest for each creator. The state of the
+// watcher is updated to include the changes.
+func (w *Watcher) Poll() (events []Event, err error) {
+	return w.PollContext(context.Background())
+}
+
+// PollContext is similar to Poll, but the requests are canceled when `ctx`
+// is done. If an error occurs, then the state of the watcher is not changed.
+func (w *Watcher) PollContext(ctx context.Context) (events []Event, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.items == nil {
+		w.items = make(map[int64]ItemState)
+	}
+
+	changed := make(map[int64]ItemState)
+	for _, creator := range w.Creators {
+		query := w.Query
+		query.CreatorID = creator
+		query.SortType = SortRecentlyUpdated
+
+		// Events for each item, from newest to oldest.
+		var found [][]Event
+	scan:
+		for page := 1; w.MaxPages <= 0 || page <= w.MaxPages; page++ {
+			query.PageNumber = page
+			results, err := SearchContext(ctx, w.Client, query)
+			if err != nil {
+				return nil, err
+			}
+			if len(results) == 0 {
+				break
+			}
+			for _, r := range results {
+				if _, ok := changed[r.AssetId]; ok {
+					continue
+				}
+				state := itemState(r)
+				prev, ok := w.items[r.AssetId]
+				if !ok {
+					found = append(found, []Event{{Type: EventNew, Result: r}})
+					changed[r.AssetId] = state
+					continue
+				}
+				updated := state.UpdatedDate.After(prev.UpdatedDate)
+				priced := state.PriceInRobux != prev.PriceInRobux || state.PriceInTickets != prev.PriceInTickets
+				if !updated && !priced {
+					if page <= w.RescanPages {
+						continue
+					}
+					// Remaining items were updated less recently.
+					break scan
+				}
+				var item []Event
+				if updated {
+					item = append(item, Event{Type: EventUpdated, Result: r, Previous: prev})
+				}
+				if priced {
+					item = append(item, Event{Type: EventPriceChanged, Result: r, Previous: prev})
+				}
+				found = append(found, item)
+				changed[r.AssetId] = state
+			}
+		}
+		for i := len(found) - 1; i >= 0; i-- {
+			events = append(events, found[i]...)
+		}
+	}
+	for id, state := range changed {
+		w.items[id] = state
+	}
+	return events, nil
+}
+
+// Run polls the catalog at the watcher's interval, calling `handle` with
+// each change, until `ctx` is done, and then returns the error of `ctx`. The
+// first poll is made immediately. A failed poll does not stop the watcher;
+// the error is passed to ErrorHandler, and the next poll is made at the
+// usual time.
+//
+// If CheckpointFile is not empty, then the state of the watcher is loaded
+// from the file, if it exists, before the first poll, and is saved to the
+// file after each poll that finds changes. An error is returned without
+// polling if the file cannot be loaded. A failed save is passed to
+// ErrorHandler, and is attempted again after the next poll.
+func (w *Watcher) Run(ctx context.Context, handle func(Event)) (err error) {
+	if w.CheckpointFile != "" {
+		if err = w.LoadCheckpointFile(w.CheckpointFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	report := func(err error) {
+		if w.ErrorHandler != nil && ctx.Err() == nil {
+			w.ErrorHandler(err)
+		}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// Whether the state has changed since it was last saved.
+	unsaved := false
+	for {
+		events, err := w.PollContext(ctx)
+		if err != nil {
+			report(err)
+		}
+		for _, event := range events {
+			handle(event)
+		}
+		unsaved = unsaved || len(events) > 0
+		if unsaved && w.CheckpointFile != "" {
+			if err = w.SaveCheckpointFile(w.CheckpointFile); err != nil {
+				report(err)
+			} else {
+				unsaved = false
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// The version of the checkpoint format written by SaveCheckpoint.
+const checkpointVersion = 1
+
+// checkpoint is the stored form of a watcher's state.
+type checkpoint struct {
+	Version int
+	Items   map[int64]ItemState
+}
+
+// SaveCheckpoint writes the state of the watcher to `wr` as JSON. The state
+// can be restored later with LoadCheckpoint.
+func (w *Watcher) SaveCheckpoint(wr io.Writer) (err error) {
+	w.mu.Lock()
+	c := checkpoint{
+		Version: checkpointVersion,
+		Items:   make(map[int64]ItemState, len(w.items)),
+	}
+	for id, state := range w.items {
+		c.Items[id] = state
+	}
+	w.mu.Unlock()
+	enc := json.NewEncoder(wr)
+	enc.SetIndent("", "\t")
+	return enc.Encode(c)
+}
+
+// LoadCheckpoint reads a state written by SaveCheckpoint from `r`, replacing
+// the state of the watcher.
+func (w *Watcher) LoadCheckpoint(r io.Reader) (err error) {
+	var c checkpoint
+	if err = json.NewDecoder(r).Decode(&c); err != nil {
+		return errors.New("JSON decode failed: " + err.Error())
+	}
+	if c.Version != checkpointVersion {
+		return fmt.Errorf("unsupported checkpoint version %d", c.Version)
+	}
+	if c.Items == nil {
+		c.Items = make(map[int64]ItemState)
+	}
+	w.mu.Lock()
+	w.items = c.Items
+	w.mu.Unlock()
+	return nil
+}
+
+// SaveCheckpointFile is similar to SaveCheckpoint, but writes to a file
+// name. The file is replaced atomically.
+func (w *Watcher) SaveCheckpointFile(filename string) (err error) {
+	return atomicfile.Write(filename, 0644, w.SaveCheckpoint)
+}
+
+// LoadCheckpointFile is similar to LoadCheckpoint, but reads from a file
+// name.
+func (w *Watcher) LoadCheckpointFile(filename string) (err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return w.LoadCheckpoint(file)
+}
@@ -0,0 +1,205 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func eventTypes(events []Event) (types []EventType) {
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// countTransport counts the requests sent through a transport. If `fail` is
+// greater than 0, then that many requests fail before any are sent.
+type countTransport struct {
+	base     http.RoundTripper
+	requests int32
+	fail     int32
+}
+
+func (t *countTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&t.requests, 1) <= atomic.LoadInt32(&t.fail) {
+		return nil, errors.New("request failed")
+	}
+	return t.base.RoundTrip(req)
+}
+
+func TestWatcher(t *testing.T) {
+	s, creator := newCatalog(50)
+	defer s.Close()
+	client := s.Client()
+	transport := &countTransport{base: client.Transport}
+	client.Transport = transport
+	w := NewWatcher(client, creator)
+
+	events, err := w.Poll()
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if len(events) != 50 {
+		t.Fatalf("expected 50 new items, got %d", len(events))
+	}
+	for _, e := range events {
+		if e.Type != EventNew {
+			t.Errorf("expected new item, got %s", e.Type)
+		}
+	}
+	// The search stops at the first unchanged item.
+	atomic.StoreInt32(&transport.requests, 0)
+	if events, err = w.Poll(); err != nil || len(events) != 0 {
+		t.Fatalf("expected no changes, got %v, %v", eventTypes(events), err)
+	}
+	if n := atomic.LoadInt32(&transport.requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+
+	// Change an item that is beyond the first page of results, without
+	// changing when it was updated. It is only found by a rescan.
+	s.Lock()
+	var changed int64
+	for id, a := range s.Assets {
+		if a.Name == "model0" {
+			a.PriceInRobux = 7
+			changed = id
+		}
+	}
+	s.Unlock()
+	if events, err = w.Poll(); err != nil || len(events) != 0 {
+		t.Fatalf("expected price change to be missed, got %v, %v", eventTypes(events), err)
+	}
+	w.RescanPages = 2
+	events, err = w.Poll()
+	w.RescanPages = 0
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventPriceChanged || events[0].Result.AssetId != changed {
+		t.Fatalf("expected price change of %d, got %+v", changed, events)
+	}
+	if events[0].Previous.PriceInRobux != 0 || events[0].Result.PriceInRobux != 7 {
+		t.Errorf("unexpected prices %d -> %d", events[0].Previous.PriceInRobux, events[0].Result.PriceInRobux)
+	}
+
+	// A new item is reported once.
+	b := s.AddAsset(int32(creator), 10, "new", nil)
+	events, err = w.Poll()
+	if err != nil || len(events) != 1 || events[0].Type != EventNew || events[0].Result.AssetId != b.Id {
+		t.Errorf("expected new item %d, got %+v, %v", b.Id, events, err)
+	}
+
+	// Update an item and change its price.
+	s.Lock()
+	for _, a := range s.Assets {
+		if a.Name == "model0" {
+			a.Updated = a.Updated.Add(time.Hour)
+			a.PriceInRobux = 8
+		}
+	}
+	s.Unlock()
+	events, err = w.Poll()
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if types := eventTypes(events); len(types) != 2 || types[0] != EventUpdated || types[1] != EventPriceChanged {
+		t.Errorf("expected update and price change, got %v", types)
+	}
+}
+
+func TestWatcherMaxPages(t *testing.T) {
+	s, creator := newCatalog(50)
+	defer s.Close()
+	w := NewWatcher(s.Client(), creator)
+	w.MaxPages = 1
+
+	events, err := w.Poll()
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if len(events) != MaxResults {
+		t.Errorf("expected %d items, got %d", MaxResults, len(events))
+	}
+}
+
+func TestWatcherCheckpoint(t *testing.T) {
+	s, creator := newCatalog(3)
+	defer s.Close()
+	w := NewWatcher(s.Client(), creator)
+	if _, err := w.Poll(); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := w.SaveCheckpoint(&buf); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	restored := NewWatcher(s.Client(), creator)
+	if err := restored.LoadCheckpoint(&buf); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if events, err := restored.Poll(); err != nil || len(events) != 0 {
+		t.Errorf("expected no changes after restore, got %v, %v", eventTypes(events), err)
+	}
+	if err := restored.LoadCheckpoint(bytes.NewBufferString(`{"Version":2}`)); err == nil {
+		t.Error("expected unsupported version to fail")
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	s, creator := newCatalog(3)
+	defer s.Close()
+	file := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	run := func() (events []Event) {
+		w := NewWatcher(s.Client(), creator)
+		w.CheckpointFile = file
+		w.Interval = 10 * time.Millisecond
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := w.Run(ctx, func(e Event) { events = append(events, e) })
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+		return events
+	}
+	if events := run(); len(events) != 3 {
+		t.Errorf("expected 3 new items, got %d", len(events))
+	}
+	if events := run(); len(events) != 0 {
+		t.Errorf("expected checkpoint to prevent repeated events, got %v", eventTypes(events))
+	}
+}
+
+func TestWatcherRunErrors(t *testing.T) {
+	s, creator := newCatalog(3)
+	defer s.Close()
+	client := s.Client()
+	client.Retry = nil
+	// The first two polls fail.
+	client.Transport = &countTransport{base: client.Transport, fail: 2}
+
+	w := NewWatcher(client, creator)
+	w.Interval = 10 * time.Millisecond
+	var errs []error
+	w.ErrorHandler = func(err error) { errs = append(errs, err) }
+	var events []Event
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := w.Run(ctx, func(e Event) { events = append(events, e) }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+	if len(events) != 3 {
+		t.Errorf("expected 3 new items after errors, got %d", len(events))
+	}
+}
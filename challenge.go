@@ -0,0 +1,106 @@
+package rbxweb
+
+import (
+	"context"
+	"strings"
+)
+
+// ChallengeType indicates the kind of challenge that must be solved to
+// complete a login.
+type ChallengeType byte
+
+const (
+	// The user must solve a captcha. The solution is the captcha response.
+	ChallengeCaptcha ChallengeType = iota + 1
+	// The user must enter a two-step verification code, which was sent to
+	// them by the website. The solution is the code.
+	ChallengeTwoStep
+)
+
+func (t ChallengeType) String() string {
+	switch t {
+	case ChallengeCaptcha:
+		return "captcha"
+	case ChallengeTwoStep:
+		return "two-step verification"
+	}
+	return "unknown challenge"
+}
+
+// challengeType returns the type of challenge indicated by an error code
+// returned by the ValidateLogin service, or 0 if the code does not indicate a
+// challenge.
+func challengeType(code string) ChallengeType {
+	code = strings.ToLower(code)
+	switch {
+	case strings.Contains(code, "captcha"):
+		return ChallengeCaptcha
+	case strings.Contains(code, "twostep"), strings.Contains(code, "two-step"):
+		return ChallengeTwoStep
+	}
+	return 0
+}
+
+// Challenge is returned as an error by Login when the website requires a
+// challenge to be solved before the login can complete.
+//
+// Id identifies the challenge, such as the captcha to be displayed, and
+// Message is the message given by the website, if any.
+//
+// A challenge is solved either by calling ResolveChallenge with the
+// solution, or by setting the client's ChallengeHandler, in which case Login
+// resolves challenges automatically.
+type Challenge struct {
+	Type    ChallengeType
+	Id      string
+	Message string
+
+	login loginData
+}
+
+func (c *Challenge) Error() string {
+	s := "login requires " + c.Type.String()
+	if c.Message != "" {
+		s = s + ": \"" + c.Message + "\""
+	}
+	return s
+}
+
+// Is reports whether the challenge matches ErrCaptchaRequired or
+// ErrTwoStepRequired.
+func (c *Challenge) Is(target error) bool {
+	switch target {
+	case ErrCaptchaRequired:
+		return c.Type == ChallengeCaptcha
+	case ErrTwoStepRequired:
+		return c.Type == ChallengeTwoStep
+	}
+	return false
+}
+
+// ChallengeHandler is called by Login to solve a challenge, returning the
+// solution. If an error is returned, then Login fails with that error.
+type ChallengeHandler func(chal *Challenge) (solution string, err error)
+
+// ResolveChallenge resubmits the login that produced `chal`, along with the
+// solution to the challenge. Another *Challenge may be returned if the
+// solution was incorrect, or if another challenge must be solved.
+func (client *Client) ResolveChallenge(chal *Challenge, solution string) (err error) {
+	return client.ResolveChallengeContext(context.Background(), chal, solution)
+}
+
+// ResolveChallengeContext is similar to ResolveChallenge, but the request is
+// canceled when `ctx` is done.
+func (client *Client) ResolveChallengeContext(ctx context.Context, chal *Challenge, solution string) (err error) {
+	data := chal.login
+	data.Challenge = chal.Id
+	switch chal.Type {
+	case ChallengeCaptcha:
+		data.IsCaptchaOn = true
+		data.CaptchaResponse = solution
+	case ChallengeTwoStep:
+		data.TwoStepCode = solution
+	}
+	client.ensureJar()
+	return client.validateLogin(ctx, data)
+}
@@ -0,0 +1,86 @@
+package rbxweb_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/anaminus/rbxweb"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// newChallengeClient returns a client of a server that requires a captcha,
+// followed by a two-step verification code, to log in.
+func newChallengeClient(t *testing.T) *rbxweb.Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data struct {
+			IsCaptchaOn     bool   `json:"isCaptchaOn"`
+			Challenge       string `json:"challenge"`
+			CaptchaResponse string `json:"captchaResponse"`
+			TwoStepCode     string `json:"twoStepCode"`
+		}
+		json.NewDecoder(r.Body).Decode(&data)
+		switch {
+		case !data.IsCaptchaOn || data.Challenge != "captcha-id" || data.CaptchaResponse != "solution":
+			io.WriteString(w, `{"d":{"IsValid":false,"Message":"Solve the captcha","ErrorCode":"CaptchaRequired","Challenge":"captcha-id"}}`)
+		case data.TwoStepCode != "123456":
+			io.WriteString(w, `{"d":{"IsValid":false,"Message":"Enter the code","ErrorCode":"TwoStepVerificationRequired","Challenge":"captcha-id"}}`)
+		default:
+			io.WriteString(w, `{"d":{"IsValid":true,"Message":"","ErrorCode":""}}`)
+		}
+	}))
+}
+
+func TestResolveChallenge(t *testing.T) {
+	client := newChallengeClient(t)
+	err := client.Login("bob", "hunter2")
+	if !rbxweb.IsCaptchaRequired(err) {
+		t.Fatalf("expected captcha, got %v", err)
+	}
+	var chal *rbxweb.Challenge
+	if !errors.As(err, &chal) {
+		t.Fatalf("expected *Challenge, got %T", err)
+	}
+	if chal.Type != rbxweb.ChallengeCaptcha || chal.Id != "captcha-id" || chal.Message != "Solve the captcha" {
+		t.Errorf("unexpected challenge %+v", chal)
+	}
+
+	if err = client.ResolveChallenge(chal, "wrong"); !rbxweb.IsCaptchaRequired(err) {
+		t.Fatalf("expected captcha again, got %v", err)
+	}
+	err = client.ResolveChallenge(chal, "solution")
+	if !rbxweb.IsTwoStepRequired(err) {
+		t.Fatalf("expected two-step verification, got %v", err)
+	}
+	errors.As(err, &chal)
+	if err = client.ResolveChallenge(chal, "123456"); err != nil {
+		t.Fatalf("resolving two-step verification failed: %v", err)
+	}
+}
+
+func TestChallengeHandler(t *testing.T) {
+	client := newChallengeClient(t)
+	var types []rbxweb.ChallengeType
+	client.ChallengeHandler = func(chal *rbxweb.Challenge) (string, error) {
+		types = append(types, chal.Type)
+		if chal.Type == rbxweb.ChallengeCaptcha {
+			return "solution", nil
+		}
+		return "123456", nil
+	}
+	if err := client.Login("bob", "hunter2"); err != nil {
+		t.Fatalf("login with handler failed: %v", err)
+	}
+	if len(types) != 2 || types[0] != rbxweb.ChallengeCaptcha || types[1] != rbxweb.ChallengeTwoStep {
+		t.Errorf("unexpected challenges %v", types)
+	}
+
+	handlerErr := errors.New("canceled by user")
+	client = newChallengeClient(t)
+	client.ChallengeHandler = func(chal *rbxweb.Challenge) (string, error) {
+		return "", handlerErr
+	}
+	if err := client.Login("bob", "hunter2"); err != handlerErr {
+		t.Errorf("expected handler error, got %v", err)
+	}
+}
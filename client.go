@@ -1,10 +1,12 @@
 package rbxweb
 
 import (
-	"errors"
+	"context"
+	"io"
 	"net/http"
 	"net/url"
-	"strconv"
+	"strings"
+	"sync"
 )
 
 // Client embeds a http.Client, and is used with every function that makes a
@@ -17,17 +19,102 @@ import (
 //     BaseDomain:                  With subdomain:
 //     roblox.com               --> www.roblox.com
 //     gametest.robloxlabs.com  --> www.gametest.robloxlabs.com
+//
+// Retry is the policy used to retry failed requests. If nil, each request is
+// attempted only once.
+//
+// Limiter, if not nil, is waited on before every request is sent, including
+// each retry. The same Limiter may be shared between several clients.
+//
+// Mutating requests, such as POSTs, automatically receive the CSRF token
+// required by the website. See CSRFToken for details.
+//
+// ChallengeHandler, if not nil, is used by Login to solve challenges, such as
+// captchas, that are required to complete a login.
+//
+// Endpoints overrides entries in DefaultEndpoints for this client. See
+// Endpoint for details.
+//
+// Concurrency is the maximum number of requests sent at once by functions
+// that make many requests, such as asset.GetInfos. If less than 1, then
+// requests are sent one at a time.
 type Client struct {
 	http.Client
-	BaseDomain string
+	BaseDomain       string
+	Retry            *RetryPolicy
+	Limiter          Limiter
+	ChallengeHandler ChallengeHandler
+	Endpoints        map[string]Endpoint
+	Concurrency      int
+
+	csrfMu    sync.Mutex
+	csrfToken string
 }
 
+// DefaultConcurrency is the Concurrency of a client returned by NewClient.
+const DefaultConcurrency = 4
+
+// NewClient returns a client that uses the default base domain, retry
+// policy, and concurrency.
 func NewClient() *Client {
+	retry := DefaultRetryPolicy
 	return &Client{
-		BaseDomain: "roblox.com",
+		BaseDomain:  "roblox.com",
+		Retry:       &retry,
+		Concurrency: DefaultConcurrency,
 	}
 }
 
+// Do sends an HTTP request and returns an HTTP response. Unlike the
+// embedded http.Client, the request is retried according to the client's
+// retry policy and limiter, and the CSRF token is attached to mutating
+// requests. Responses compressed with gzip are decompressed, regardless of
+// the transport used. Every request made through the client goes through Do.
+func (client *Client) Do(req *http.Request) (resp *http.Response, err error) {
+	return client.doCSRF(req)
+}
+
+// send waits on the client's limiter, then sends a single request. As with
+// http.Client.Do, the body of the request is always closed, even when the
+// request is not sent.
+func (client *Client) send(req *http.Request) (resp *http.Response, err error) {
+	if client.Limiter != nil {
+		if err = client.Limiter.Wait(req.Context(), req.URL.Hostname()); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	resp, err = client.Client.Do(acceptGzip(req))
+	if err != nil {
+		return resp, err
+	}
+	decompressResponse(resp)
+	return resp, nil
+}
+
+// Get issues a GET request to the specified URL.
+func (client *Client) Get(url string) (resp *http.Response, err error) {
+	return client.GetContext(context.Background(), url)
+}
+
+// Head issues a HEAD request to the specified URL.
+func (client *Client) Head(url string) (resp *http.Response, err error) {
+	return client.HeadContext(context.Background(), url)
+}
+
+// Post issues a POST request to the specified URL.
+func (client *Client) Post(url string, contentType string, body io.Reader) (resp *http.Response, err error) {
+	return client.PostContext(context.Background(), url, contentType, body)
+}
+
+// PostForm issues a POST request to the specified URL, with `data` encoded
+// as the request body.
+func (client *Client) PostForm(url string, data url.Values) (resp *http.Response, err error) {
+	return client.PostFormContext(context.Background(), url, data)
+}
+
 // GetURL constructs a URL using BaseDomain and the given arguments, with HTTP
 // as the protocol.
 //
@@ -60,15 +147,56 @@ func (client *Client) GetSecureURL(subdomain string, path string, query url.Valu
 	return
 }
 
+// GetContext issues a GET request to the specified URL. The request is
+// canceled when `ctx` is done.
+func (client *Client) GetContext(ctx context.Context, url string) (resp *http.Response, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// HeadContext issues a HEAD request to the specified URL. The request is
+// canceled when `ctx` is done.
+func (client *Client) HeadContext(ctx context.Context, url string) (resp *http.Response, err error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// PostContext issues a POST request to the specified URL. The request is
+// canceled when `ctx` is done.
+func (client *Client) PostContext(ctx context.Context, url string, contentType string, body io.Reader) (resp *http.Response, err error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != `` {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return client.Do(req)
+}
+
+// PostFormContext issues a POST request to the specified URL, with `data`
+// encoded as the request body. The request is canceled when `ctx` is done.
+func (client *Client) PostFormContext(ctx context.Context, url string, data url.Values) (resp *http.Response, err error) {
+	return client.PostContext(ctx, url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+}
+
 // AssertResp checks whether a HTTP response errored. Also errors if the
-// response has a non-2XX status code.
+// response has a non-2XX status code, in which case the returned error is an
+// *Error.
 func (client *Client) AssertResp(resp *http.Response, err error) error {
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode < 200 && resp.StatusCode >= 300 {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		e := newRespError(resp)
 		resp.Body.Close()
-		return errors.New(strconv.Itoa(resp.StatusCode) + ": " + http.StatusText(resp.StatusCode))
+		return e
 	}
 	return nil
 }
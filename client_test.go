@@ -0,0 +1,129 @@
+package rbxweb_test
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/anaminus/rbxweb"
+	"github.com/anaminus/rbxweb/user"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serverTransport sends every request to a test server, regardless of the
+// host of the request.
+type serverTransport struct {
+	url *url.URL
+}
+
+func (t serverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = t.url.Scheme
+	r.URL.Host = t.url.Host
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if resp != nil {
+		resp.Request = req
+	}
+	return resp, err
+}
+
+// newTestClient returns a client whose requests are handled by `handler`.
+// Failed requests are retried without waiting.
+func newTestClient(t *testing.T, handler http.Handler) *rbxweb.Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	client := rbxweb.NewClient()
+	client.Transport = serverTransport{u}
+	client.Retry.MinBackoff = time.Millisecond
+	client.Retry.MaxBackoff = time.Millisecond
+	return client
+}
+
+func TestContext(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		io.WriteString(w, "1")
+	}))
+
+	resp, err := client.GetContext(context.Background(), client.GetURL(`www`, `/fast`, nil))
+	if err = client.AssertResp(resp, err); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.GetContext(ctx, client.GetURL(`www`, `/slow`, nil))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err = user.GetIdFromNameContext(ctx, client, "bob"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled, got %v", err)
+	}
+}
+
+func TestGzipResponse(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			io.WriteString(w, "plain")
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		io.WriteString(zw, "compressed")
+		zw.Close()
+	}))
+	resp, err := client.Get("http://www.roblox.com/")
+	if err = client.AssertResp(resp, err); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "compressed" {
+		t.Errorf("expected decompressed body, got %q, %v", body, err)
+	}
+	if resp.Header.Get("Content-Encoding") != "" || !resp.Uncompressed {
+		t.Errorf("response still marked as compressed")
+	}
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Wait(ctx context.Context, host string) error {
+	return errors.New("limiter failed")
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestLimiterClosesBody(t *testing.T) {
+	client := rbxweb.NewClient()
+	client.Limiter = failingLimiter{}
+	body := &closeRecorder{Reader: strings.NewReader("x")}
+	req, _ := http.NewRequest("POST", "http://www.roblox.com/", body)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected limiter error")
+	}
+	if !body.closed {
+		t.Error("request body was not closed")
+	}
+}
@@ -0,0 +1,122 @@
+package rbxweb
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// acceptGzip requests a gzip-compressed response, unless the request already
+// specifies an encoding. Compression is handled by the client rather than by
+// the transport, so that responses are decompressed regardless of the
+// transport used.
+func acceptGzip(req *http.Request) *http.Request {
+	if req.Header.Get("Accept-Encoding") != "" || req.Header.Get("Range") != "" {
+		return req
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set("Accept-Encoding", "gzip")
+	return r
+}
+
+// decompressResponse replaces the body of a gzip-compressed response with
+// one that decompresses it.
+func decompressResponse(resp *http.Response) {
+	if resp.Uncompressed || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// gzipBody decompresses a response body. The gzip header is not read until
+// the first call to Read, so that an empty body does not cause an error
+// until it is read.
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+func (g *gzipBody) Read(p []byte) (n int, err error) {
+	if g.err != nil {
+		return 0, g.err
+	}
+	if g.zr == nil {
+		if g.zr, g.err = gzip.NewReader(g.body); g.err != nil {
+			return 0, g.err
+		}
+	}
+	return g.zr.Read(p)
+}
+
+func (g *gzipBody) Close() error {
+	return g.body.Close()
+}
+
+// GzipBody returns a reader that produces the gzip-compressed form of `r`.
+// The data is compressed as it is read, so that `r` is not read into memory
+// all at once. `r` is not read until the first call to Read.
+//
+// The returned reader must be closed when finished. Close waits until `r` is
+// no longer being read, after which `r` may be safely reused, such as by
+// rewinding it.
+func GzipBody(r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	return &gzipPipe{r: r, pr: pr, pw: pw, done: make(chan struct{})}
+}
+
+// gzipPipe compresses a reader in a separate goroutine, which is started by
+// the first call to Read.
+type gzipPipe struct {
+	r  io.Reader
+	pr *io.PipeReader
+	pw *io.PipeWriter
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+	done    chan struct{}
+}
+
+func (g *gzipPipe) start() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.started || g.closed {
+		return
+	}
+	g.started = true
+	go func() {
+		defer close(g.done)
+		zw := gzip.NewWriter(g.pw)
+		_, err := io.Copy(zw, g.r)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		g.pw.CloseWithError(err)
+	}()
+}
+
+func (g *gzipPipe) Read(p []byte) (n int, err error) {
+	g.start()
+	return g.pr.Read(p)
+}
+
+func (g *gzipPipe) Close() error {
+	g.mu.Lock()
+	g.closed = true
+	started := g.started
+	g.mu.Unlock()
+	// Closing the reader causes pending and future writes to fail, which
+	// stops the goroutine.
+	err := g.pr.Close()
+	if started {
+		<-g.done
+	}
+	return err
+}
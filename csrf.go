@@ -0,0 +1,112 @@
+package rbxweb
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// The header used by the website to send and receive CSRF tokens.
+const csrfHeader = "X-CSRF-TOKEN"
+
+// CSRFToken returns the CSRF token currently cached by the client, or an
+// empty string if no token has been received.
+//
+// The website rejects mutating requests that lack a valid token with a 403
+// response that contains a fresh token in its headers. When this happens,
+// the client caches the new token, and sends the request again once with the
+// token attached. The cached token is then attached to every following
+// mutating request.
+func (client *Client) CSRFToken() string {
+	client.csrfMu.Lock()
+	defer client.csrfMu.Unlock()
+	return client.csrfToken
+}
+
+// SetCSRFToken sets the CSRF token that is attached to mutating requests. An
+// empty string clears the cached token.
+func (client *Client) SetCSRFToken(token string) {
+	client.csrfMu.Lock()
+	defer client.csrfMu.Unlock()
+	client.csrfToken = token
+}
+
+// FetchCSRFToken obtains a CSRF token for the client, if it does not already
+// have one. A request without a body is sent to the named endpoint, which the
+// website rejects with a fresh token. The request is not sent again with the
+// token. `args` are used to construct the URL, as with EndpointURL.
+//
+// This is useful before sending a request whose body cannot be rewound, since
+// such a request cannot be sent again when the website responds with a new
+// token. If the website does not respond with a token, then no error is
+// returned, and the client remains without a token.
+func (client *Client) FetchCSRFToken(ctx context.Context, name string, args ...interface{}) (err error) {
+	if client.CSRFToken() != "" {
+		return nil
+	}
+	req, err := client.NewRequest(ctx, name, nil, nil, args...)
+	if err != nil {
+		return err
+	}
+	resp, err := client.doRetry(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if token := resp.Header.Get(csrfHeader); token != "" && resp.StatusCode == http.StatusForbidden {
+		client.SetCSRFToken(token)
+	}
+	return nil
+}
+
+// isMutating returns whether a request with the given method may change
+// state on the website, and so requires a CSRF token.
+func isMutating(method string) bool {
+	switch method {
+	case "", "GET", "HEAD", "OPTIONS", "TRACE":
+		return false
+	}
+	return true
+}
+
+// doCSRF sends `req`, attaching the cached CSRF token if the request is
+// mutating. If the website rejects the request with a new token, then the
+// token is cached, and the request is sent again once with the new token.
+func (client *Client) doCSRF(req *http.Request) (resp *http.Response, err error) {
+	if !isMutating(req.Method) {
+		return client.doRetry(req)
+	}
+
+	token := client.CSRFToken()
+	r := req
+	if token != "" {
+		r = req.Clone(req.Context())
+		r.Header.Set(csrfHeader, token)
+	}
+	resp, err = client.doRetry(r)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+	newToken := resp.Header.Get(csrfHeader)
+	if newToken == "" || newToken == token {
+		return resp, err
+	}
+	client.SetCSRFToken(newToken)
+
+	// The request can be replayed only if its body can be rewound.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	r = req.Clone(req.Context())
+	if req.GetBody != nil {
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	r.Header.Set(csrfHeader, newToken)
+	return client.doRetry(r)
+}
@@ -0,0 +1,118 @@
+package rbxweb_test
+
+import (
+	"context"
+	"github.com/anaminus/rbxweb"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newCSRFClient returns a client of a server that rejects mutating requests
+// without the token "token", echoes the body of accepted requests, and
+// counts the requests it receives.
+func newCSRFClient(t *testing.T) (client *rbxweb.Client, requests *int32) {
+	requests = new(int32)
+	client = newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == "GET" {
+			io.WriteString(w, r.Header.Get("X-CSRF-TOKEN"))
+			return
+		}
+		if r.Header.Get("X-CSRF-TOKEN") != "token" {
+			w.Header().Set("X-CSRF-TOKEN", "token")
+			http.Error(w, "Token Validation Failed", http.StatusForbidden)
+			return
+		}
+		w.Write(body)
+	}))
+	return client, requests
+}
+
+func TestCSRFReplay(t *testing.T) {
+	client, requests := newCSRFClient(t)
+	url := client.GetURL(`www`, `/`, nil)
+
+	resp, err := client.Post(url, "text/plain", strings.NewReader("content"))
+	if err = client.AssertResp(resp, err); err != nil {
+		t.Fatalf("post failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "content" {
+		t.Errorf("expected replayed body %q, got %q", "content", body)
+	}
+	if n := atomic.LoadInt32(requests); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+	if client.CSRFToken() != "token" {
+		t.Errorf("expected token to be cached, got %q", client.CSRFToken())
+	}
+
+	resp, err = client.Post(url, "text/plain", strings.NewReader("content"))
+	if err = client.AssertResp(resp, err); err != nil {
+		t.Fatalf("second post failed: %v", err)
+	}
+	resp.Body.Close()
+	if n := atomic.LoadInt32(requests); n != 3 {
+		t.Errorf("expected cached token to be sent, got %d requests", n)
+	}
+
+	// The token is not sent with requests that do not mutate.
+	resp, err = client.Get(url)
+	if err = client.AssertResp(resp, err); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if len(body) != 0 {
+		t.Errorf("expected no token with GET, got %q", body)
+	}
+}
+
+func TestCSRFNotRewindable(t *testing.T) {
+	client, requests := newCSRFClient(t)
+	url := client.GetURL(`www`, `/`, nil)
+
+	// A body that cannot be rewound is not replayed, but the token is still
+	// cached for the next request.
+	body := io.MultiReader(strings.NewReader("content"))
+	resp, err := client.Post(url, "text/plain", body)
+	if err = client.AssertResp(resp, err); !rbxweb.IsUnauthorized(err) {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+	if client.CSRFToken() != "token" {
+		t.Errorf("expected token to be cached, got %q", client.CSRFToken())
+	}
+
+	client.SetCSRFToken("")
+	if client.CSRFToken() != "" {
+		t.Errorf("expected token to be cleared")
+	}
+}
+
+func TestFetchCSRFToken(t *testing.T) {
+	client, requests := newCSRFClient(t)
+	if err := client.FetchCSRFToken(context.Background(), rbxweb.EndpointLogout); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if client.CSRFToken() != "token" {
+		t.Errorf("expected token to be cached, got %q", client.CSRFToken())
+	}
+	// The rejected request is not sent again with the token.
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+	if err := client.FetchCSRFToken(context.Background(), rbxweb.EndpointLogout); err != nil {
+		t.Fatalf("second fetch failed: %v", err)
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected cached token to be used, got %d requests", n)
+	}
+}
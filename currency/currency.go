@@ -2,26 +2,33 @@
 package currency
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
 	"github.com/anaminus/rbxweb"
-	"io"
 	"net/url"
-	"os"
-	"time"
+	"strconv"
 )
 
+// TradeTickets places an order on the currency exchange to trade `tickets`
+// for `robux`. If `limit` is true, then a limit order is placed, otherwise a
+// market order is placed. `split` sets whether the trade may be split.
+//
+// This function requires the client to be logged in.
 func TradeTickets(client *rbxweb.Client, tickets int64, robux int64, limit bool, split bool) (err error) {
-	page := client.GetURL(`www`, `/My/Money.aspx`, nil)
+	return TradeTicketsContext(context.Background(), client, tickets, robux, limit, split)
+}
+
+// TradeTicketsContext is similar to TradeTickets, but the requests are
+// canceled when `ctx` is done.
+func TradeTicketsContext(ctx context.Context, client *rbxweb.Client, tickets int64, robux int64, limit bool, split bool) (err error) {
+	page := client.EndpointURL(rbxweb.EndpointMoneyPage, nil)
 	query := url.Values{
 		"__EVENTTARGET":                                                           {"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$SubmitTradeButton"},
 		"__VIEWSTATE":                                                             {},
 		"__EVENTVALIDATION":                                                       {},
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$HaveCurrencyDropDownList": {"Tickets"},
-		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$HaveAmountTextBoxRestyle": {strconv.Itoa(tickets)},
+		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$HaveAmountTextBoxRestyle": {strconv.FormatInt(tickets, 10)},
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$WantCurrencyDropDownList": {"Robux"},
-		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$WantAmountTextBox":        {strconv.Itoa(robux)},
+		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$WantAmountTextBox":        {strconv.FormatInt(robux, 10)},
 	}
 	if limit {
 		query.Set("ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$OrderType", "LimitOrderRadioButton")
@@ -33,20 +40,31 @@ func TradeTickets(client *rbxweb.Client, tickets int64, robux int64, limit bool,
 	} else {
 		query.Set("ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$AllowSplitTradesCheckBox", "off")
 	}
-	err = client.DoRawPost(page, query)
+	err = client.DoRawPostContext(ctx, page, query)
 	return
 }
 
+// TradeRobux places an order on the currency exchange to trade `robux` for
+// `tickets`. If `limit` is true, then a limit order is placed, otherwise a
+// market order is placed. `split` sets whether the trade may be split.
+//
+// This function requires the client to be logged in.
 func TradeRobux(client *rbxweb.Client, robux int64, tickets int64, limit bool, split bool) (err error) {
-	page := client.GetURL(`www`, `/My/Money.aspx`, nil)
+	return TradeRobuxContext(context.Background(), client, robux, tickets, limit, split)
+}
+
+// TradeRobuxContext is similar to TradeRobux, but the requests are canceled
+// when `ctx` is done.
+func TradeRobuxContext(ctx context.Context, client *rbxweb.Client, robux int64, tickets int64, limit bool, split bool) (err error) {
+	page := client.EndpointURL(rbxweb.EndpointMoneyPage, nil)
 	query := url.Values{
 		"__EVENTTARGET":                                                           {"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$SubmitTradeButton"},
 		"__VIEWSTATE":                                                             {},
 		"__EVENTVALIDATION":                                                       {},
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$HaveCurrencyDropDownList": {"Robux"},
-		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$HaveAmountTextBoxRestyle": {strconv.Itoa(robux)},
+		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$HaveAmountTextBoxRestyle": {strconv.FormatInt(robux, 10)},
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$WantCurrencyDropDownList": {"Tickets"},
-		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$WantAmountTextBox":        {strconv.Itoa(tickets)},
+		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$WantAmountTextBox":        {strconv.FormatInt(tickets, 10)},
 	}
 	if limit {
 		query.Set("ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$OrderType", "LimitOrderRadioButton")
@@ -58,6 +76,6 @@ func TradeRobux(client *rbxweb.Client, robux int64, tickets int64, limit bool, s
 	} else {
 		query.Set("ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$AllowSplitTradesCheckBox", "off")
 	}
-	err = client.DoRawPost(page, query)
+	err = client.DoRawPostContext(ctx, page, query)
 	return
 }
@@ -1,6 +1,7 @@
 package rbxweb
 
 import (
+	"context"
 	"golang.org/x/net/html"
 	"net/url"
 )
@@ -59,8 +60,14 @@ func recurseNode(node *html.Node, f func(*html.Node) bool) {
 //
 // Whether the client needs to be logged in varies depending on the request.
 func (client *Client) DoRawPost(page string, params url.Values) (err error) {
+	return client.DoRawPostContext(context.Background(), page, params)
+}
+
+// DoRawPostContext is similar to DoRawPost, but both requests are canceled
+// when `ctx` is done.
+func (client *Client) DoRawPostContext(ctx context.Context, page string, params url.Values) (err error) {
 	// Get form data from URL
-	resp, err := client.Get(page)
+	resp, err := client.GetContext(ctx, page)
 	if err = client.AssertResp(resp, err); err != nil {
 		return err
 	}
@@ -94,7 +101,7 @@ func (client *Client) DoRawPost(page string, params url.Values) (err error) {
 	}
 
 	// Post to URL with parameters
-	resp, err = client.PostForm(page, params)
+	resp, err = client.PostFormContext(ctx, page, params)
 	if err = client.AssertResp(resp, err); err != nil {
 		return err
 	}
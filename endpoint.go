@@ -0,0 +1,150 @@
+package rbxweb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Endpoint describes where and how a request to a particular service is
+// sent.
+//
+// Method is the HTTP method of the request. Scheme is the protocol, either
+// "http" or "https". Subdomain is added before the client's BaseDomain, as
+// with GetURL. If Host is not empty, then it is used in place of both the
+// subdomain and base domain, which allows an endpoint to be pointed at a
+// different server entirely, such as a local mirror. Path is the part of the
+// URL after the domain. Some paths contain formatting verbs, which are filled
+// in by the arguments passed to EndpointURL. Gzip indicates whether the
+// endpoint accepts a request body compressed with gzip.
+type Endpoint struct {
+	Method    string
+	Scheme    string
+	Subdomain string
+	Host      string
+	Path      string
+	Gzip      bool
+}
+
+// Names of the endpoints used by this package and its subpackages.
+const (
+	EndpointLogin         = "Login"
+	EndpointLogout        = "Logout"
+	EndpointLoginPage     = "LoginPage"
+	EndpointUserInfo      = "UserInfo"
+	EndpointCurrentUser   = "CurrentUser"
+	EndpointUserProfile   = "UserProfile"
+	EndpointUserName      = "UserName"
+	EndpointCatalogSearch = "CatalogSearch"
+	EndpointLatestModel   = "LatestModel"
+	EndpointAssetItem     = "AssetItem"
+	EndpointAsset         = "Asset"
+	EndpointAssetVersions = "AssetVersions"
+	EndpointRevertVersion = "RevertVersion"
+	EndpointUpload        = "Upload"
+	EndpointProductInfo   = "ProductInfo"
+	EndpointSetHandler    = "SetHandler"
+	EndpointGroupPage     = "GroupPage"
+	EndpointMoneyPage     = "MoneyPage"
+)
+
+// DefaultEndpoints contains the endpoints used by a client, unless they are
+// overridden by the client's Endpoints field. Endpoints used with DoRawPost
+// refer to the page containing the form. Their Method is ignored, because
+// DoRawPost always requests the page with GET, then submits the form with
+// POST.
+var DefaultEndpoints = map[string]Endpoint{
+	EndpointLogin:         {Method: "POST", Scheme: "https", Subdomain: `www`, Path: `/Services/Secure/LoginService.asmx/ValidateLogin`},
+	EndpointLogout:        {Method: "POST", Scheme: "https", Subdomain: `www`, Path: `/authentication/logout`},
+	EndpointLoginPage:     {Method: "GET", Scheme: "https", Subdomain: `www`, Path: `/newlogin`},
+	EndpointUserInfo:      {Method: "GET", Scheme: "http", Subdomain: `www`, Path: `/MobileAPI/UserInfo`},
+	EndpointCurrentUser:   {Method: "GET", Scheme: "http", Subdomain: `www`, Path: `/Game/GetCurrentUser.ashx`},
+	EndpointUserProfile:   {Method: "HEAD", Scheme: "http", Subdomain: `www`, Path: `/User.aspx`},
+	EndpointUserName:      {Method: "GET", Scheme: "http", Subdomain: `api`, Path: `/users/%d`},
+	EndpointCatalogSearch: {Method: "GET", Scheme: "http", Subdomain: `www`, Path: `/catalog/json`},
+	EndpointLatestModel:   {Method: "GET", Scheme: "http", Subdomain: `api`, Path: `/catalog/json`},
+	EndpointAssetItem:     {Method: "HEAD", Scheme: "http", Subdomain: `www`, Path: `/_-item`},
+	EndpointAsset:         {Method: "GET", Scheme: "http", Subdomain: `www`, Path: `/asset/`},
+	EndpointAssetVersions: {Method: "GET", Scheme: "http", Subdomain: `api`, Path: `/assets/%d/versions`},
+	EndpointRevertVersion: {Method: "POST", Scheme: "http", Subdomain: `www`, Path: `/places/revert`},
+	EndpointUpload:        {Method: "POST", Scheme: "http", Subdomain: `www`, Path: `/Data/Upload.ashx`, Gzip: true},
+	EndpointProductInfo:   {Method: "GET", Scheme: "http", Subdomain: `api`, Path: `/marketplace/productinfo`},
+	EndpointSetHandler:    {Method: "POST", Scheme: "http", Subdomain: `www`, Path: `/Sets/SetHandler.ashx`},
+	EndpointGroupPage:     {Scheme: "http", Subdomain: `www`, Path: `/My/Groups.aspx`},
+	EndpointMoneyPage:     {Scheme: "http", Subdomain: `www`, Path: `/My/Money.aspx`},
+}
+
+// Endpoint returns the named endpoint. An endpoint in the client's Endpoints
+// field takes precedence over one in DefaultEndpoints. `ok` is false if the
+// endpoint does not exist in either.
+func (client *Client) Endpoint(name string) (endpoint Endpoint, ok bool) {
+	if endpoint, ok = client.Endpoints[name]; ok {
+		return endpoint, true
+	}
+	endpoint, ok = DefaultEndpoints[name]
+	return endpoint, ok
+}
+
+// SetEndpoint overrides the named endpoint for the client.
+func (client *Client) SetEndpoint(name string, endpoint Endpoint) {
+	if client.Endpoints == nil {
+		client.Endpoints = make(map[string]Endpoint)
+	}
+	client.Endpoints[name] = endpoint
+}
+
+// URL constructs a URL for the endpoint, using `baseDomain` unless the
+// endpoint has a Host. `args` are used to format the path. If `query` is not
+// nil, then it is encoded into query parameters and added to the end of the
+// URL.
+func (endpoint Endpoint) URL(baseDomain string, query url.Values, args ...interface{}) string {
+	scheme := endpoint.Scheme
+	if scheme == `` {
+		scheme = `http`
+	}
+	u := scheme + `://`
+	if endpoint.Host != `` {
+		u = u + endpoint.Host
+	} else {
+		if endpoint.Subdomain != `` {
+			u = u + endpoint.Subdomain + `.`
+		}
+		u = u + baseDomain
+	}
+	if len(args) > 0 {
+		u = u + fmt.Sprintf(endpoint.Path, args...)
+	} else {
+		u = u + endpoint.Path
+	}
+	if query != nil {
+		u = u + `?` + query.Encode()
+	}
+	return u
+}
+
+// EndpointURL constructs a URL for the named endpoint. It returns an empty
+// string if the endpoint does not exist.
+func (client *Client) EndpointURL(name string, query url.Values, args ...interface{}) string {
+	endpoint, ok := client.Endpoint(name)
+	if !ok {
+		return ``
+	}
+	return endpoint.URL(client.BaseDomain, query, args...)
+}
+
+// NewRequest creates a request for the named endpoint, using the method of
+// the endpoint. The request is canceled when `ctx` is done. `query` and
+// `args` are used to construct the URL, as with EndpointURL.
+func (client *Client) NewRequest(ctx context.Context, name string, query url.Values, body io.Reader, args ...interface{}) (req *http.Request, err error) {
+	endpoint, ok := client.Endpoint(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown endpoint %q", name)
+	}
+	method := endpoint.Method
+	if method == `` {
+		method = "GET"
+	}
+	return http.NewRequestWithContext(ctx, method, endpoint.URL(client.BaseDomain, query, args...), body)
+}
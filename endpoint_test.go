@@ -0,0 +1,69 @@
+package rbxweb_test
+
+import (
+	"context"
+	"github.com/anaminus/rbxweb"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestEndpointURL(t *testing.T) {
+	client := rbxweb.NewClient()
+	if u := client.EndpointURL(rbxweb.EndpointUserName, nil, 5); u != "http://api.roblox.com/users/5" {
+		t.Errorf("unexpected URL %q", u)
+	}
+	client.BaseDomain = "gametest.robloxlabs.com"
+	query := url.Values{"id": {"1"}}
+	if u := client.EndpointURL(rbxweb.EndpointProductInfo, query); u != "http://api.gametest.robloxlabs.com/marketplace/productinfo?id=1" {
+		t.Errorf("unexpected URL %q", u)
+	}
+	if u := client.EndpointURL("Nonexistent", nil); u != "" {
+		t.Errorf("expected empty URL for unknown endpoint, got %q", u)
+	}
+	if _, err := client.NewRequest(context.Background(), "Nonexistent", nil, nil); err == nil {
+		t.Error("expected unknown endpoint to fail")
+	}
+}
+
+func TestSetEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mirror/UserInfo" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, `{"UserID":1,"UserName":"bob"}`)
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	client := rbxweb.NewClient()
+	client.SetEndpoint(rbxweb.EndpointUserInfo, rbxweb.Endpoint{
+		Method: "GET",
+		Scheme: "http",
+		Host:   u.Host,
+		Path:   "/mirror/UserInfo",
+	})
+	if endpoint, _ := client.Endpoint(rbxweb.EndpointUserInfo); endpoint.Host != u.Host {
+		t.Errorf("expected override, got %+v", endpoint)
+	}
+	if got := client.EndpointURL(rbxweb.EndpointUserInfo, nil); got != srv.URL+"/mirror/UserInfo" {
+		t.Errorf("unexpected URL %q", got)
+	}
+
+	client.LoginCookie("session")
+	user, err := client.CheckSession()
+	if err != nil {
+		t.Fatalf("check session failed: %v", err)
+	}
+	if user.Id != 1 || user.Name != "bob" {
+		t.Errorf("unexpected user %+v", user)
+	}
+
+	// The override does not affect other clients.
+	if endpoint, _ := rbxweb.NewClient().Endpoint(rbxweb.EndpointUserInfo); endpoint.Host != "" {
+		t.Errorf("override leaked into defaults: %+v", endpoint)
+	}
+}
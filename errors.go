@@ -0,0 +1,141 @@
+package rbxweb
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Sentinel errors that an *Error may match with errors.Is.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrCaptchaRequired    = errors.New("captcha required")
+	ErrTwoStepRequired    = errors.New("two-step verification required")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+// The maximum number of bytes of a response body that are kept in an Error.
+const maxErrorBody = 512
+
+// Error describes a failed request to the website.
+//
+// StatusCode is the HTTP status code of the response, and is 0 if the
+// failure was not determined by the status code. Endpoint is the URL of the
+// request, without query parameters. Body contains the beginning of the
+// response body, if any. Code and Message are the error code and message
+// reported by the website, if any.
+type Error struct {
+	StatusCode int
+	Endpoint   string
+	Body       string
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	var s string
+	if e.StatusCode != 0 {
+		s = strconv.Itoa(e.StatusCode) + ": " + http.StatusText(e.StatusCode)
+	} else {
+		s = "request failed"
+	}
+	if e.Code != "" {
+		s = s + ": error code " + e.Code
+	}
+	if e.Message != "" {
+		s = s + ": \"" + e.Message + "\""
+	}
+	if e.Endpoint != "" {
+		s = s + " (" + e.Endpoint + ")"
+	}
+	return s
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrCaptchaRequired:
+		return challengeType(e.Code) == ChallengeCaptcha
+	case ErrTwoStepRequired:
+		return challengeType(e.Code) == ChallengeTwoStep
+	case ErrInvalidCredentials:
+		return isCredentialsCode(e.Code)
+	}
+	return false
+}
+
+// isCredentialsCode returns whether an error code returned by the
+// ValidateLogin service indicates that the username or password is wrong.
+func isCredentialsCode(code string) bool {
+	code = strings.ToLower(code)
+	return strings.Contains(code, "credential") ||
+		strings.Contains(code, "password") ||
+		strings.Contains(code, "username")
+}
+
+// newRespError creates an Error from a response, consuming the first part of
+// the response body.
+func newRespError(resp *http.Response) *Error {
+	e := &Error{StatusCode: resp.StatusCode}
+	if resp.Request != nil && resp.Request.URL != nil {
+		u := *resp.Request.URL
+		u.RawQuery = ""
+		e.Endpoint = u.String()
+	}
+	if resp.Body != nil {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		e.Body = string(b)
+	}
+	return e
+}
+
+// IsNotFound returns whether `err` indicates that a resource does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized returns whether `err` indicates that the client is not
+// permitted to make the request, such as when it is not logged in.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsRateLimited returns whether `err` indicates that the client has made too
+// many requests.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsSessionExpired returns whether `err` indicates that the client's session
+// is no longer valid, and that the client must log in again.
+func IsSessionExpired(err error) bool {
+	return errors.Is(err, ErrSessionExpired)
+}
+
+// IsInvalidCredentials returns whether `err` indicates that a login failed
+// because the username or password is wrong.
+func IsInvalidCredentials(err error) bool {
+	return errors.Is(err, ErrInvalidCredentials)
+}
+
+// IsCaptchaRequired returns whether `err` indicates that a captcha must be
+// solved before the request can succeed.
+func IsCaptchaRequired(err error) bool {
+	return errors.Is(err, ErrCaptchaRequired)
+}
+
+// IsTwoStepRequired returns whether `err` indicates that a two-step
+// verification code must be entered before the request can succeed.
+func IsTwoStepRequired(err error) bool {
+	return errors.Is(err, ErrTwoStepRequired)
+}
@@ -0,0 +1,80 @@
+package rbxweb_test
+
+import (
+	"errors"
+	"github.com/anaminus/rbxweb"
+	"github.com/anaminus/rbxweb/user"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAssertResp(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.WriteHeader(code)
+		io.WriteString(w, strings.Repeat("x", 1000))
+	}))
+
+	tests := []struct {
+		code int
+		is   func(error) bool
+	}{
+		{http.StatusNotFound, rbxweb.IsNotFound},
+		{http.StatusUnauthorized, rbxweb.IsUnauthorized},
+		{http.StatusForbidden, rbxweb.IsUnauthorized},
+		{http.StatusTooManyRequests, rbxweb.IsRateLimited},
+	}
+	for _, test := range tests {
+		path := "/" + strconv.Itoa(test.code)
+		resp, err := client.Get(client.GetURL(`www`, path, nil) + "?secret=1")
+		err = client.AssertResp(resp, err)
+		if !test.is(err) {
+			t.Errorf("%d: unexpected error %v", test.code, err)
+		}
+		var e *rbxweb.Error
+		if !errors.As(err, &e) {
+			t.Fatalf("%d: expected *Error, got %T", test.code, err)
+		}
+		if e.StatusCode != test.code {
+			t.Errorf("%d: unexpected status code %d", test.code, e.StatusCode)
+		}
+		if e.Endpoint != "http://www.roblox.com"+path {
+			t.Errorf("%d: unexpected endpoint %q", test.code, e.Endpoint)
+		}
+		if len(e.Body) != 512 {
+			t.Errorf("%d: expected body to be truncated, got %d bytes", test.code, len(e.Body))
+		}
+	}
+
+	resp, err := client.Get(client.GetURL(`www`, `/200`, nil))
+	if err = client.AssertResp(resp, err); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestLoginError(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"d":{"IsValid":false,"Message":"Account is locked","ErrorCode":"AccountLocked"}}`)
+	}))
+	err := client.Login("bob", "hunter2")
+	if rbxweb.IsCaptchaRequired(err) {
+		t.Errorf("unexpected captcha %v", err)
+	}
+	var e *rbxweb.Error
+	if !errors.As(err, &e) || e.Code != "AccountLocked" || e.Message != "Account is locked" {
+		t.Errorf("unexpected error %#v", err)
+	}
+}
+
+func TestGetCurrentIdUnauthorized(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html>Login</html>")
+	}))
+	if _, err := user.GetCurrentId(client); err != rbxweb.ErrUnauthorized {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@
 package group
 
 import (
+	"context"
 	"github.com/anaminus/rbxweb"
 	"net/url"
 	"strconv"
@@ -13,8 +14,14 @@ import (
 //
 // This function requires the client to be logged in.
 func Shout(client *rbxweb.Client, groupID int32, message string) (success bool) {
-	page := client.GetURL(`www`, `/My/Groups.aspx`, url.Values{"gid": {strconv.FormatInt(int64(groupId), 10)}})
-	err := client.DoRawPost(page, url.Values{
+	return ShoutContext(context.Background(), client, groupID, message)
+}
+
+// ShoutContext is similar to Shout, but the requests are canceled when `ctx`
+// is done.
+func ShoutContext(ctx context.Context, client *rbxweb.Client, groupID int32, message string) (success bool) {
+	page := client.EndpointURL(rbxweb.EndpointGroupPage, url.Values{"gid": {strconv.FormatInt(int64(groupID), 10)}})
+	err := client.DoRawPostContext(ctx, page, url.Values{
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$GroupStatusPane$StatusTextBox":               {message},
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$GroupStatusPane$StatusSubmitButton":          {},
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$rbxGroupRoleSetMembersPane$currentRoleSetID": {},
@@ -30,8 +37,14 @@ func Shout(client *rbxweb.Client, groupID int32, message string) (success bool)
 //
 // This function requires the client to be logged in.
 func Wall(client *rbxweb.Client, groupID int32, message string) (success bool) {
-	page := client.GetURL(`www`, `/My/Groups.aspx`, url.Values{"gid": {strconv.FormatInt(int64(groupID), 10)}})
-	err := client.DoRawPost(page, url.Values{
+	return WallContext(context.Background(), client, groupID, message)
+}
+
+// WallContext is similar to Wall, but the requests are canceled when `ctx`
+// is done.
+func WallContext(ctx context.Context, client *rbxweb.Client, groupID int32, message string) (success bool) {
+	page := client.EndpointURL(rbxweb.EndpointGroupPage, url.Values{"gid": {strconv.FormatInt(int64(groupID), 10)}})
+	err := client.DoRawPostContext(ctx, page, url.Values{
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$GroupWallPane$NewPost":                       {message},
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$GroupWallPane$NewPostButton":                 {},
 		"ctl00$ctl00$cphRoblox$cphMyRobloxContent$rbxGroupRoleSetMembersPane$currentRoleSetID": {},
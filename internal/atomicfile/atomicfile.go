@@ -0,0 +1,40 @@
+// Writes files atomically.
+package atomicfile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Write creates or replaces the file `filename` with the data written by
+// `write`. The data is written to a temporary file in the same directory,
+// which is renamed to `filename` only if writing succeeds, so that the file
+// is never left partially written. The file is given the permissions `perm`,
+// except on Windows.
+func Write(filename string, perm os.FileMode, write func(w io.Writer) error) (err error) {
+	file, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+	if err = file.Chmod(perm); err != nil && runtime.GOOS != "windows" {
+		return err
+	}
+	if err = write(file); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filename)
+}
@@ -0,0 +1,125 @@
+package rbxweb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter controls the rate at which requests are sent by a Client.
+type Limiter interface {
+	// Wait blocks until a request may be sent to `host`, or until `ctx` is
+	// done, in which case the context's error is returned.
+	Wait(ctx context.Context, host string) error
+}
+
+// RateLimiter is a Limiter that keeps a token bucket for each host, so that
+// requests to one subdomain, such as www, do not use up the allowance of
+// another, such as api.
+//
+// Rate is the number of requests per second allowed for each host, and Burst
+// is the number of requests that may be sent at once after a period of
+// inactivity. A host may be given its own rate with SetHostRate. A Rate of 0
+// or less allows requests to be sent without limit.
+//
+// A RateLimiter is safe for concurrent use, and may be shared between
+// several clients by assigning it to the Limiter field of each.
+type RateLimiter struct {
+	Rate  float64
+	Burst int
+
+	mu      sync.Mutex
+	rates   map[string]hostRate
+	buckets map[string]*bucket
+}
+
+type hostRate struct {
+	rate  float64
+	burst int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that allows `rate` requests per
+// second to each host, with bursts of up to `burst` requests.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{Rate: rate, Burst: burst}
+}
+
+// SetHostRate sets the rate and burst for a single host, such as
+// "api.roblox.com", overriding Rate and Burst.
+func (l *RateLimiter) SetHostRate(host string, rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rates == nil {
+		l.rates = make(map[string]hostRate)
+	}
+	l.rates[host] = hostRate{rate: rate, burst: burst}
+	delete(l.buckets, host)
+}
+
+// reserve takes a token from the bucket of `host`, returning how long to
+// wait before the token may be used.
+func (l *RateLimiter) reserve(host string, now time.Time) (wait time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := hostRate{rate: l.Rate, burst: l.Burst}
+	if hr, ok := l.rates[host]; ok {
+		r = hr
+	}
+	if r.rate <= 0 {
+		return 0, false
+	}
+	if r.burst < 1 {
+		r.burst = 1
+	}
+
+	if l.buckets == nil {
+		l.buckets = make(map[string]*bucket)
+	}
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: float64(r.burst), last: now}
+		l.buckets[host] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * r.rate
+	if b.tokens > float64(r.burst) {
+		b.tokens = float64(r.burst)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0, true
+	}
+	return time.Duration(-b.tokens / r.rate * float64(time.Second)), true
+}
+
+// cancel returns an unused token to the bucket of `host`.
+func (l *RateLimiter) cancel(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.buckets[host]; ok {
+		b.tokens++
+	}
+}
+
+// Wait implements the Limiter interface.
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	wait, ok := l.reserve(host, time.Now())
+	if !ok || wait == 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel(host)
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
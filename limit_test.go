@@ -0,0 +1,111 @@
+package rbxweb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := NewRateLimiter(2, 3)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if wait, ok := l.reserve("www.roblox.com", now); !ok || wait != 0 {
+			t.Fatalf("request %d: expected no wait, got %v, %v", i, wait, ok)
+		}
+	}
+	if wait, _ := l.reserve("www.roblox.com", now); wait != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms, got %v", wait)
+	}
+	if wait, _ := l.reserve("www.roblox.com", now); wait != time.Second {
+		t.Errorf("expected to wait 1s, got %v", wait)
+	}
+
+	// Another host has its own bucket.
+	if wait, _ := l.reserve("api.roblox.com", now); wait != 0 {
+		t.Errorf("expected separate bucket, got wait %v", wait)
+	}
+
+	// Tokens are refilled over time, up to the burst.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if wait, _ := l.reserve("www.roblox.com", now); wait != 0 {
+			t.Fatalf("request %d after refill: expected no wait, got %v", i, wait)
+		}
+	}
+	if wait, _ := l.reserve("www.roblox.com", now); wait == 0 {
+		t.Error("expected refill to be limited by the burst")
+	}
+}
+
+func TestRateLimiterHostRate(t *testing.T) {
+	l := NewRateLimiter(0, 0)
+	now := time.Now()
+	if _, ok := l.reserve("www.roblox.com", now); ok {
+		t.Error("expected zero rate to be unlimited")
+	}
+	l.SetHostRate("api.roblox.com", 1, 1)
+	if wait, ok := l.reserve("api.roblox.com", now); !ok || wait != 0 {
+		t.Errorf("expected no wait, got %v, %v", wait, ok)
+	}
+	if wait, _ := l.reserve("api.roblox.com", now); wait != time.Second {
+		t.Errorf("expected to wait 1s, got %v", wait)
+	}
+	if _, ok := l.reserve("www.roblox.com", now); ok {
+		t.Error("expected host rate to apply only to its host")
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	ctx := context.Background()
+	if err := l.Wait(ctx, "www.roblox.com"); err != nil {
+		t.Fatalf("wait failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(ctx, "www.roblox.com"); err != context.Canceled {
+		t.Errorf("expected canceled, got %v", err)
+	}
+	// The canceled wait returns its token, so only one request is queued.
+	if wait, _ := l.reserve("www.roblox.com", time.Now()); wait > time.Second {
+		t.Errorf("expected canceled token to be returned, got wait %v", wait)
+	}
+}
+
+type hostRecorder struct {
+	hosts []string
+}
+
+func (r *hostRecorder) Wait(ctx context.Context, host string) error {
+	r.hosts = append(r.hosts, host)
+	return nil
+}
+
+func TestClientLimiter(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests++; requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	client := NewClient()
+	client.Retry.MinBackoff = time.Millisecond
+	limiter := &hostRecorder{}
+	client.Limiter = limiter
+	resp, err := client.Get(srv.URL)
+	if err = client.AssertResp(resp, err); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	resp.Body.Close()
+	// Each attempt waits on the limiter.
+	if len(limiter.hosts) != 2 || limiter.hosts[0] != u.Hostname() || limiter.hosts[1] != u.Hostname() {
+		t.Errorf("unexpected waits %v", limiter.hosts)
+	}
+}
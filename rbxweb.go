@@ -1,3 +1,9 @@
 // The rbxweb package provides an interface to many of ROBLOX's web-based
 // services.
 package rbxweb
+
+// Creator describes the user that created an asset.
+type Creator struct {
+	Id   int32
+	Name string
+}
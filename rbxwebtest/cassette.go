@@ -0,0 +1,257 @@
+package rbxwebtest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/anaminus/rbxweb"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode determines whether a Recorder sends requests or replays them.
+type Mode byte
+
+const (
+	// Requests are answered from the cassette, and are never sent.
+	ModeReplay Mode = iota
+	// Requests are sent, and each request and response is added to the
+	// cassette.
+	ModeRecord
+)
+
+// The value that replaces sensitive data in a cassette.
+const scrubbed = "SCRUBBED"
+
+// Headers whose values are replaced in recorded responses.
+var sensitiveHeaders = []string{"Cookie", "X-Csrf-Token", "Authorization"}
+
+// Interaction is a single request and response stored in a cassette.
+// Request bodies are not stored, since they may contain credentials.
+type Interaction struct {
+	Method       string
+	Host         string
+	Path         string
+	Query        string
+	StatusCode   int
+	Header       http.Header
+	Body         string
+	BodyEncoding string `json:",omitempty"`
+}
+
+// cassette is the stored form of a recording.
+type cassette struct {
+	Interactions []*Interaction
+}
+
+// Recorder is a http.RoundTripper that records requests and responses to a
+// cassette file, and replays them later without a network.
+//
+// Requests are matched to interactions by method, path and query, where
+// query parameters are compared regardless of their order. When several
+// interactions match, they are replayed in the order they were recorded, and
+// the last is repeated once the others are used up.
+//
+// Security cookies and CSRF tokens are replaced before being written to the
+// cassette.
+type Recorder struct {
+	// Mode is the mode of the recorder.
+	Mode Mode
+	// Path is the file name of the cassette.
+	Path string
+	// Transport sends requests in ModeRecord. If nil, then
+	// http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette cassette
+	used     map[*Interaction]bool
+}
+
+// NewRecorder returns a Recorder for the cassette file `path`. In
+// ModeReplay, the cassette is loaded from the file, and an error is returned
+// if it cannot be read. In ModeRecord, the cassette starts empty, and is
+// written by Save.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		Mode: mode,
+		Path: path,
+		used: make(map[*Interaction]bool),
+	}
+	if mode == ModeReplay {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		if err := json.NewDecoder(file).Decode(&r.cassette); err != nil {
+			return nil, errors.New("JSON decode failed: " + err.Error())
+		}
+	}
+	return r, nil
+}
+
+// Client returns a new client whose requests go through the recorder.
+func (r *Recorder) Client() *rbxweb.Client {
+	client := rbxweb.NewClient()
+	client.Jar = rbxweb.NewCookieJar()
+	client.Transport = r
+	return client
+}
+
+// Save writes the cassette to the recorder's file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := json.MarshalIndent(r.cassette, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.Path, append(b, '\n'), 0644)
+}
+
+// normalizeQuery returns a query string with its parameters sorted.
+func normalizeQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.Mode == ModeRecord {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	query := normalizeQuery(req.URL.RawQuery)
+
+	r.mu.Lock()
+	var match *Interaction
+	for _, in := range r.cassette.Interactions {
+		if in.Method != req.Method || in.Path != req.URL.Path || in.Query != query {
+			continue
+		}
+		match = in
+		if !r.used[in] {
+			break
+		}
+	}
+	if match != nil {
+		r.used[match] = true
+	}
+	r.mu.Unlock()
+
+	if match == nil {
+		return nil, fmt.Errorf("no recorded interaction for %s %s", req.Method, req.URL)
+	}
+	body := []byte(match.Body)
+	if match.BodyEncoding == "base64" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(match.Body); err != nil {
+			return nil, err
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.StatusCode, http.StatusText(match.StatusCode)),
+		StatusCode:    match.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        match.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// Compressed bodies are stored decompressed, so that cassettes can be
+	// read and edited.
+	if len(body) > 0 && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.Uncompressed = true
+	}
+	resp.ContentLength = int64(len(body))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	in := &Interaction{
+		Method:     req.Method,
+		Host:       req.URL.Host,
+		Path:       req.URL.Path,
+		Query:      normalizeQuery(req.URL.RawQuery),
+		StatusCode: resp.StatusCode,
+		Header:     scrubHeader(resp.Header),
+	}
+	if utf8.Valid(body) {
+		in.Body = string(body)
+	} else {
+		in.Body = base64.StdEncoding.EncodeToString(body)
+		in.BodyEncoding = "base64"
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// scrubHeader returns a copy of a response header with sensitive values
+// replaced.
+func scrubHeader(header http.Header) http.Header {
+	h := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if h.Get(name) != "" {
+			h.Set(name, scrubbed)
+		}
+	}
+	cookies := h["Set-Cookie"]
+	for i, cookie := range cookies {
+		name, rest, ok := strings.Cut(cookie, "=")
+		if !ok || strings.TrimSpace(name) != securityCookie {
+			continue
+		}
+		if j := strings.IndexByte(rest, ';'); j >= 0 {
+			cookies[i] = name + "=" + scrubbed + rest[j:]
+		} else {
+			cookies[i] = name + "=" + scrubbed
+		}
+	}
+	return h
+}
@@ -0,0 +1,104 @@
+package rbxwebtest_test
+
+import (
+	"github.com/anaminus/rbxweb/asset"
+	"github.com/anaminus/rbxweb/rbxwebtest"
+	"github.com/anaminus/rbxweb/user"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	u := s.AddUser("bob", "hunter2")
+	a := s.AddAsset(u.Id, 10, "Thing", []byte("content"))
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := rbxwebtest.NewRecorder(path, rbxwebtest.ModeRecord)
+	if err != nil {
+		t.Fatalf("new recorder failed: %v", err)
+	}
+	rec.Transport = s.Client().Transport
+	client := rec.Client()
+	if err := client.Login("bob", "hunter2"); err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	if info, err := asset.GetInfo(client, a.Id); err != nil || info.Name != "Thing" {
+		t.Fatalf("unexpected info %+v, %v", info, err)
+	}
+	if err := client.Logout(); err != nil {
+		t.Fatalf("logout failed: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	s.Close()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	cassette := string(b)
+	for _, secret := range []string{"rbxwebtest-session", "rbxwebtest-csrf", "hunter2"} {
+		if strings.Contains(cassette, secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+	if !strings.Contains(cassette, ".ROBLOSECURITY=SCRUBBED") {
+		t.Error("expected scrubbed security cookie")
+	}
+
+	// The server is closed, so every response must come from the cassette.
+	rep, err := rbxwebtest.NewRecorder(path, rbxwebtest.ModeReplay)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	client = rep.Client()
+	if err := client.Login("bob", "hunter2"); err != nil {
+		t.Fatalf("replayed login failed: %v", err)
+	}
+	if info, err := asset.GetInfo(client, a.Id); err != nil || info.Name != "Thing" {
+		t.Errorf("unexpected replayed info %+v, %v", info, err)
+	}
+	if err := client.Logout(); err != nil {
+		t.Errorf("replayed logout failed: %v", err)
+	}
+	if _, err := user.GetInfo(client); err == nil {
+		t.Error("expected unrecorded request to fail")
+	}
+	if _, err := rbxwebtest.NewRecorder(path+".missing", rbxwebtest.ModeReplay); err == nil {
+		t.Error("expected missing cassette to fail")
+	}
+}
+
+func TestRecorderGzip(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	s.Gzip = true
+	a := s.AddAsset(1, 10, "Thing", nil)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, _ := rbxwebtest.NewRecorder(path, rbxwebtest.ModeRecord)
+	rec.Transport = s.Client().Transport
+	if info, err := asset.GetInfo(rec.Client(), a.Id); err != nil || info.Name != "Thing" {
+		t.Fatalf("unexpected info %+v, %v", info, err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	s.Close()
+
+	// Compressed responses are stored as readable text.
+	b, _ := os.ReadFile(path)
+	if cassette := string(b); strings.Contains(cassette, "base64") || strings.Contains(cassette, `"gzip"`) || !strings.Contains(cassette, "Thing") {
+		t.Errorf("expected decompressed body in cassette:\n%s", cassette)
+	}
+	rep, err := rbxwebtest.NewRecorder(path, rbxwebtest.ModeReplay)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if info, err := asset.GetInfo(rep.Client(), a.Id); err != nil || info.Name != "Thing" {
+		t.Errorf("unexpected replayed info %+v, %v", info, err)
+	}
+}
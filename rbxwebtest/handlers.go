@@ -0,0 +1,752 @@
+package rbxwebtest
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The name of the cookie holding a session.
+const securityCookie = ".ROBLOSECURITY"
+
+// The values of the validation fields in emulated forms.
+const (
+	viewState       = "rbxwebtest-viewstate"
+	eventValidation = "rbxwebtest-eventvalidation"
+)
+
+// Names of asset types accepted by the upload service.
+var typeNames = map[string]int32{
+	"Image":   1,
+	"TShirt":  2,
+	"Audio":   3,
+	"Mesh":    4,
+	"Lua":     5,
+	"Hat":     8,
+	"Place":   9,
+	"Model":   10,
+	"Shirt":   11,
+	"Pants":   12,
+	"Decal":   13,
+	"Gear":    19,
+	"Badge":   21,
+	"Plugin":  38,
+	"Package": 32,
+}
+
+// handler returns the handler that serves every emulated service.
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, f func(http.ResponseWriter, *http.Request)) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.Gzip && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+				gw := &gzipResponseWriter{ResponseWriter: w}
+				defer gw.Close()
+				w = gw
+			}
+			f(w, r)
+		})
+	}
+	handle("/Services/Secure/LoginService.asmx/ValidateLogin", s.handleLogin)
+	handle("/authentication/logout", s.handleLogout)
+	handle("/newlogin", s.handleLoginPage)
+	handle("/MobileAPI/UserInfo", s.handleUserInfo)
+	handle("/Game/GetCurrentUser.ashx", s.handleCurrentUser)
+	handle("/User.aspx", s.handleUserProfile)
+	handle("/users/", s.handleUserName)
+	handle("/catalog/json", s.handleCatalog)
+	handle("/_-item", s.handleItem)
+	handle("/", s.handleItemPage)
+	handle("/asset/", s.handleAsset)
+	handle("/cdn/", s.handleCDN)
+	handle("/assets/", s.handleVersions)
+	handle("/places/revert", s.handleRevert)
+	handle("/Data/Upload.ashx", s.handleUpload)
+	handle("/marketplace/productinfo", s.handleProductInfo)
+	handle("/Sets/SetHandler.ashx", s.handleSet)
+	handle("/My/Groups.aspx", s.handleGroups)
+	handle("/My/Money.aspx", s.handleMoney)
+	return mux
+}
+
+// gzipResponseWriter compresses a response with gzip.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	zw *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(statusCode int) {
+	if w.zw == nil {
+		h := w.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		w.zw = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.zw == nil {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.zw.Write(b)
+}
+
+// Close finishes the compressed response, if anything was written.
+func (w *gzipResponseWriter) Close() error {
+	if w.zw == nil {
+		return nil
+	}
+	return w.zw.Close()
+}
+
+// user returns the user that the request is logged in as, or nil.
+func (s *Server) user(r *http.Request) *User {
+	cookie, err := r.Cookie(securityCookie)
+	if err != nil {
+		return nil
+	}
+	id, ok := s.sessions[cookie.Value]
+	if !ok {
+		return nil
+	}
+	return s.Users[id]
+}
+
+// checkCSRF verifies the CSRF token of a request. If the token is not
+// valid, then a 403 response with the correct token is written, and false
+// is returned.
+func (s *Server) checkCSRF(w http.ResponseWriter, r *http.Request) bool {
+	if s.CSRFToken == "" || r.Header.Get("X-CSRF-TOKEN") == s.CSRFToken {
+		return true
+	}
+	w.Header().Set("X-CSRF-TOKEN", s.CSRFToken)
+	http.Error(w, "Token Validation Failed", http.StatusForbidden)
+	return false
+}
+
+// requireMethod writes an error and returns false if the request does not
+// use the given method.
+func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+// writeJSON writes `v` as a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeLoginPage writes the page that unauthenticated users are shown.
+func writeLoginPage(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, "<!DOCTYPE html><html><head><title>Login</title></head><body>Please log in.</body></html>")
+}
+
+// formatDate formats a time in the style of the catalog.
+func formatDate(t time.Time) string {
+	return "/Date(" + strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10) + ")/"
+}
+
+// formatCount formats a number with thousands separators.
+func formatCount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, "POST") {
+		return
+	}
+	var data struct {
+		UserName        string `json:"userName"`
+		Password        string `json:"password"`
+		IsCaptchaOn     bool   `json:"isCaptchaOn"`
+		Challenge       string `json:"challenge"`
+		CaptchaResponse string `json:"captchaResponse"`
+		TwoStepCode     string `json:"twoStepCode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	type result struct {
+		IsValid   bool
+		Message   string
+		ErrorCode string
+		Challenge string `json:",omitempty"`
+	}
+	respond := func(d result) {
+		writeJSON(w, map[string]result{"d": d})
+	}
+
+	if s.Captcha != "" && (!data.IsCaptchaOn || data.CaptchaResponse != s.Captcha) {
+		respond(result{ErrorCode: "CaptchaRequired", Message: "Please complete the captcha.", Challenge: "rbxwebtest-captcha"})
+		return
+	}
+	var user *User
+	for _, u := range s.Users {
+		if strings.EqualFold(u.Name, data.UserName) {
+			user = u
+			break
+		}
+	}
+	if user == nil || user.Password != data.Password {
+		respond(result{ErrorCode: "InvalidCredentials", Message: "Incorrect username or password."})
+		return
+	}
+	if s.TwoStepCode != "" && data.TwoStepCode != s.TwoStepCode {
+		respond(result{ErrorCode: "TwoStepVerificationRequired", Message: "Enter the code sent to your email.", Challenge: "rbxwebtest-twostep"})
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     securityCookie,
+		Value:    s.newSession(user.Id),
+		Domain:   s.BaseDomain,
+		Path:     "/",
+		HttpOnly: true,
+	})
+	respond(result{IsValid: true})
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, "POST") || !s.checkCSRF(w, r) {
+		return
+	}
+	if cookie, err := r.Cookie(securityCookie); err == nil {
+		delete(s.sessions, cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:   securityCookie,
+		Domain: s.BaseDomain,
+		Path:   "/",
+		MaxAge: -1,
+	})
+}
+
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	writeLoginPage(w)
+}
+
+func (s *Server) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	user := s.user(r)
+	if user == nil {
+		http.Redirect(w, r, "/newlogin?ReturnUrl="+url.QueryEscape(r.URL.Path), http.StatusFound)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"UserID":                  user.Id,
+		"UserName":                user.Name,
+		"RobuxBalance":            user.Robux,
+		"TicketsBalance":          user.Tickets,
+		"ThumbnailUrl":            "",
+		"IsAnyBuildersClubMember": false,
+	})
+}
+
+func (s *Server) handleCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user := s.user(r)
+	if user == nil {
+		io.WriteString(w, "null")
+		return
+	}
+	io.WriteString(w, strconv.FormatInt(int64(user.Id), 10))
+}
+
+func (s *Server) handleUserProfile(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if name := query.Get("UserName"); name != "" {
+		for _, user := range s.Users {
+			if strings.EqualFold(user.Name, name) {
+				http.Redirect(w, r, "/User.aspx?ID="+strconv.FormatInt(int64(user.Id), 10), http.StatusFound)
+				return
+			}
+		}
+		http.NotFound(w, r)
+		return
+	}
+	id, _ := strconv.ParseInt(query.Get("ID"), 10, 32)
+	user, ok := s.Users[int32(id)]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, "<!DOCTYPE html><html><body><h1>"+template.HTMLEscapeString(user.Name)+"</h1></body></html>")
+}
+
+func (s *Server) handleUserName(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/users/"), 10, 32)
+	user, ok := s.Users[int32(id)]
+	if err != nil || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"Id":       user.Id,
+		"Username": user.Name,
+	})
+}
+
+func (s *Server) handleCatalog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	creatorId, _ := strconv.ParseInt(query.Get("CreatorID"), 10, 32)
+	keyword := strings.ToLower(query.Get("Keyword"))
+	includeNotForSale, _ := strconv.ParseBool(query.Get("IncludeNotForSale"))
+
+	assets := make([]*Asset, 0, len(s.Assets))
+	for _, asset := range s.Assets {
+		if creatorId != 0 && asset.CreatorId != int32(creatorId) {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(asset.Name), keyword) {
+			continue
+		}
+		if !includeNotForSale && !asset.IsForSale {
+			continue
+		}
+		assets = append(assets, asset)
+	}
+	switch query.Get("SortType") {
+	case "3", "RecentlyUpdated":
+		sort.Slice(assets, func(i, j int) bool {
+			if assets[i].Updated.Equal(assets[j].Updated) {
+				return assets[i].Id > assets[j].Id
+			}
+			return assets[i].Updated.After(assets[j].Updated)
+		})
+	default:
+		sort.Slice(assets, func(i, j int) bool { return assets[i].Id < assets[j].Id })
+	}
+
+	perPage, _ := strconv.Atoi(query.Get("ResultsPerPage"))
+	if perPage <= 0 || perPage > 42 {
+		perPage = 42
+	}
+	page, _ := strconv.Atoi(query.Get("PageNumber"))
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * perPage
+	if start > len(assets) {
+		start = len(assets)
+	}
+	end := start + perPage
+	if end > len(assets) {
+		end = len(assets)
+	}
+
+	results := make([]map[string]interface{}, 0, end-start)
+	for _, asset := range assets[start:end] {
+		var creatorName string
+		if user, ok := s.Users[asset.CreatorId]; ok {
+			creatorName = user.Name
+		}
+		price := "--"
+		if asset.IsForSale {
+			if asset.PriceInRobux == 0 {
+				price = "Free"
+			} else {
+				price = formatCount(asset.PriceInRobux)
+			}
+		}
+		id := strconv.FormatInt(asset.Id, 10)
+		results = append(results, map[string]interface{}{
+			"AssetId":                asset.Id,
+			"Name":                   asset.Name,
+			"Url":                    "/item.aspx?id=" + id,
+			"PriceInRobux":           price,
+			"PriceInTickets":         "",
+			"Updated":                asset.Updated.Format("Jan 2, 2006"),
+			"Favorited":              formatCount(int64(asset.Favorited)),
+			"Sales":                  formatCount(int64(asset.Sales)),
+			"Remaining":              "",
+			"Creator":                creatorName,
+			"CreatorUrl":             "/User.aspx?ID=" + strconv.FormatInt(int64(asset.CreatorId), 10),
+			"PrivateSales":           "",
+			"PriceView":              0,
+			"BestPrice":              "",
+			"ContentRatingTypeID":    0,
+			"AssetTypeID":            asset.TypeId,
+			"CreatorID":              asset.CreatorId,
+			"CreatedDate":            formatDate(asset.Created),
+			"UpdatedDate":            formatDate(asset.Updated),
+			"IsForSale":              asset.IsForSale,
+			"IsPublicDomain":         asset.IsPublic,
+			"IsLimited":              false,
+			"IsLimitedUnique":        false,
+			"MinimumMembershipLevel": 0,
+		})
+	}
+	writeJSON(w, results)
+}
+
+func (s *Server) handleItem(w http.ResponseWriter, r *http.Request) {
+	avid, _ := strconv.ParseInt(r.URL.Query().Get("avid"), 10, 64)
+	asset, _ := s.findVersion(avid)
+	if asset == nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, "/unnamed-item?id="+strconv.FormatInt(asset.Id, 10), http.StatusFound)
+}
+
+// handleItemPage serves item pages, whose paths are formed from the name of
+// the item, such as "/unnamed-item".
+func (s *Server) handleItemPage(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	asset, ok := s.Assets[id]
+	if !strings.HasSuffix(r.URL.Path, "-item") || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, "<!DOCTYPE html><html><body><h1>"+template.HTMLEscapeString(asset.Name)+"</h1></body></html>")
+}
+
+func (s *Server) handleAsset(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var version *AssetVersion
+	if avid := query.Get("assetversionid"); avid != "" {
+		id, _ := strconv.ParseInt(avid, 10, 64)
+		_, version = s.findVersion(id)
+	} else {
+		id, _ := strconv.ParseInt(query.Get("id"), 10, 64)
+		if asset, ok := s.Assets[id]; ok {
+			version = asset.Versions[len(asset.Versions)-1]
+		}
+	}
+	if version == nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, "http://"+s.CDNHost+"/cdn/"+strconv.FormatInt(version.Id, 10), http.StatusFound)
+}
+
+func (s *Server) handleCDN(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/cdn/"), 10, 64)
+	_, version := s.findVersion(id)
+	if version == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(version.Content)))
+	w.Write(version.Content)
+}
+
+// The number of versions in each page returned by the versions service.
+const versionsPerPage = 10
+
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/assets/"), "/versions")
+	id, err := strconv.ParseInt(path, 10, 64)
+	asset, ok := s.Assets[id]
+	if err != nil || !ok || !strings.HasSuffix(r.URL.Path, "/versions") {
+		http.NotFound(w, r)
+		return
+	}
+	user := s.user(r)
+	if user == nil || user.Id != asset.CreatorId {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	results := make([]map[string]interface{}, 0, versionsPerPage)
+	// Versions are listed from newest to oldest.
+	start := len(asset.Versions) - (page-1)*versionsPerPage
+	for i := start - 1; i >= 0 && i >= start-versionsPerPage; i-- {
+		version := asset.Versions[i]
+		results = append(results, map[string]interface{}{
+			"Id":              version.Id,
+			"AssetId":         asset.Id,
+			"VersionNumber":   version.Number,
+			"CreatorType":     "User",
+			"CreatorTargetId": version.CreatorId,
+			"Created":         version.Created,
+			"Updated":         version.Created,
+		})
+	}
+	writeJSON(w, results)
+}
+
+func (s *Server) handleRevert(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, "POST") {
+		return
+	}
+	user := s.user(r)
+	if user == nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	if !s.checkCSRF(w, r) {
+		return
+	}
+	avid, _ := strconv.ParseInt(r.FormValue("assetVersionID"), 10, 64)
+	asset, version := s.findVersion(avid)
+	if asset == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if asset.CreatorId != user.Id {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	s.addVersion(asset, user.Id, version.Content)
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, "POST") {
+		return
+	}
+	user := s.user(r)
+	if user == nil {
+		writeLoginPage(w)
+		return
+	}
+	if !s.checkCSRF(w, r) {
+		return
+	}
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if s.MaxUploadSize > 0 && int64(len(content)) > s.MaxUploadSize {
+		// The upload service reports failures in the body of a successful
+		// response.
+		io.WriteString(w, "The file is too large.")
+		return
+	}
+
+	query := r.URL.Query()
+	assetId, _ := strconv.ParseInt(query.Get("assetid"), 10, 64)
+	if assetId == 0 {
+		typeName := query.Get("type")
+		typeId, ok := typeNames[typeName]
+		if !ok {
+			n, err := strconv.ParseInt(typeName, 10, 32)
+			if err != nil {
+				http.Error(w, "Invalid asset type", http.StatusBadRequest)
+				return
+			}
+			typeId = int32(n)
+		}
+		name := query.Get("name")
+		if name == "" {
+			name = "Unnamed"
+		}
+		asset := s.addAsset(user.Id, typeId, name, content)
+		asset.Description = query.Get("description")
+		genre, _ := strconv.ParseInt(query.Get("genreTypeId"), 10, 32)
+		asset.GenreTypeId = int32(genre)
+		asset.IsPublic, _ = strconv.ParseBool(query.Get("isPublic"))
+		asset.AllowComments, _ = strconv.ParseBool(query.Get("allowComments"))
+		io.WriteString(w, strconv.FormatInt(asset.Versions[0].Id, 10))
+		return
+	}
+
+	asset, ok := s.Assets[assetId]
+	if !ok {
+		http.Error(w, "Asset not found", http.StatusNotFound)
+		return
+	}
+	if asset.CreatorId != user.Id {
+		http.Error(w, "You are not authorized to modify this asset.", http.StatusForbidden)
+		return
+	}
+	version := s.addVersion(asset, user.Id, content)
+	io.WriteString(w, strconv.FormatInt(version.Id, 10))
+}
+
+func (s *Server) handleProductInfo(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.URL.Query().Get("assetId"), 10, 64)
+	asset, ok := s.Assets[id]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var creatorName string
+	if user, ok := s.Users[asset.CreatorId]; ok {
+		creatorName = user.Name
+	}
+	writeJSON(w, map[string]interface{}{
+		"AssetId":     asset.Id,
+		"ProductId":   asset.Id,
+		"Name":        asset.Name,
+		"Description": asset.Description,
+		"AssetTypeId": asset.TypeId,
+		"Creator": map[string]interface{}{
+			"Id":   asset.CreatorId,
+			"Name": creatorName,
+		},
+		"Created":                asset.Created,
+		"Updated":                asset.Updated,
+		"PriceInRobux":           asset.PriceInRobux,
+		"PriceInTickets":         0,
+		"Sales":                  asset.Sales,
+		"IsNew":                  false,
+		"IsForSale":              asset.IsForSale,
+		"IsPublicDomain":         asset.IsPublic,
+		"IsLimited":              false,
+		"IsLimitedUnique":        false,
+		"Remaining":              0,
+		"MinimumMembershipLevel": 0,
+		"ContentRatingTypeId":    0,
+	})
+}
+
+func (s *Server) handleSet(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, "POST") {
+		return
+	}
+	if s.user(r) == nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	if !s.checkCSRF(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	if query.Get("rqtype") != "addtoset" {
+		http.Error(w, "Unknown request type", http.StatusBadRequest)
+		return
+	}
+	assetId, _ := strconv.ParseInt(query.Get("assetId"), 10, 64)
+	setId, _ := strconv.ParseInt(query.Get("setId"), 10, 32)
+	if _, ok := s.Assets[assetId]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+	s.Sets[int32(setId)] = append(s.Sets[int32(setId)], assetId)
+}
+
+// writeForm writes an ASP.NET-style form page with the validation fields
+// and the given inputs.
+func writeForm(w http.ResponseWriter, inputs map[string]string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, "<!DOCTYPE html><html><body><form method=\"post\">\n")
+	io.WriteString(w, "<input type=\"hidden\" name=\"__VIEWSTATE\" value=\""+viewState+"\"/>\n")
+	io.WriteString(w, "<input type=\"hidden\" name=\"__EVENTVALIDATION\" value=\""+eventValidation+"\"/>\n")
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		io.WriteString(w, "<input name=\""+template.HTMLEscapeString(name)+"\" value=\""+template.HTMLEscapeString(inputs[name])+"\"/>\n")
+	}
+	io.WriteString(w, "</form></body></html>")
+}
+
+// checkForm parses a posted form and verifies its validation fields.
+func checkForm(w http.ResponseWriter, r *http.Request) bool {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if r.PostForm.Get("__VIEWSTATE") != viewState || r.PostForm.Get("__EVENTVALIDATION") != eventValidation {
+		http.Error(w, "Validation of viewstate MAC failed.", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+const groupPrefix = "ctl00$ctl00$cphRoblox$cphMyRobloxContent$"
+
+func (s *Server) handleGroups(w http.ResponseWriter, r *http.Request) {
+	if s.user(r) == nil {
+		writeLoginPage(w)
+		return
+	}
+	gid, err := strconv.ParseInt(r.URL.Query().Get("gid"), 10, 32)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != "POST" {
+		writeForm(w, map[string]string{
+			groupPrefix + "GroupStatusPane$StatusSubmitButton":          "Submit",
+			groupPrefix + "GroupWallPane$NewPostButton":                 "Post",
+			groupPrefix + "rbxGroupRoleSetMembersPane$currentRoleSetID": "1",
+		})
+		return
+	}
+	if !checkForm(w, r) {
+		return
+	}
+	form := r.PostForm
+	switch {
+	case form.Get(groupPrefix+"GroupStatusPane$StatusSubmitButton") != "":
+		s.GroupShouts[int32(gid)] = form.Get(groupPrefix + "GroupStatusPane$StatusTextBox")
+	case form.Get(groupPrefix+"GroupWallPane$NewPostButton") != "":
+		s.GroupWalls[int32(gid)] = append(s.GroupWalls[int32(gid)], form.Get(groupPrefix+"GroupWallPane$NewPost"))
+	}
+	writeForm(w, nil)
+}
+
+const moneyPrefix = "ctl00$ctl00$cphRoblox$cphMyRobloxContent$ctl00$"
+
+func (s *Server) handleMoney(w http.ResponseWriter, r *http.Request) {
+	user := s.user(r)
+	if user == nil {
+		writeLoginPage(w)
+		return
+	}
+	if r.Method != "POST" {
+		writeForm(w, nil)
+		return
+	}
+	if !checkForm(w, r) {
+		return
+	}
+	form := r.PostForm
+	trade := Trade{
+		UserId: user.Id,
+		Have:   form.Get(moneyPrefix + "HaveCurrencyDropDownList"),
+		Want:   form.Get(moneyPrefix + "WantCurrencyDropDownList"),
+		Limit:  form.Get(moneyPrefix+"OrderType") == "LimitOrderRadioButton",
+		Split:  form.Get(moneyPrefix+"AllowSplitTradesCheckBox") == "on",
+	}
+	trade.HaveAmt, _ = strconv.ParseInt(form.Get(moneyPrefix+"HaveAmountTextBoxRestyle"), 10, 64)
+	trade.WantAmt, _ = strconv.ParseInt(form.Get(moneyPrefix+"WantAmountTextBox"), 10, 64)
+	s.Trades = append(s.Trades, trade)
+	writeForm(w, nil)
+}
@@ -0,0 +1,252 @@
+// Provides a fake ROBLOX website for testing code that uses rbxweb.
+//
+// A Server emulates the services used by rbxweb and its subpackages, keeping
+// users, assets and other state in memory. Client returns a rbxweb.Client
+// whose requests are all sent to the server, regardless of the URL.
+package rbxwebtest
+
+import (
+	"github.com/anaminus/rbxweb"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// User is a user account on a Server.
+type User struct {
+	Id       int32
+	Name     string
+	Password string
+	Robux    int64
+	Tickets  int64
+}
+
+// Asset is an asset on a Server.
+type Asset struct {
+	Id            int64
+	TypeId        int32
+	Name          string
+	Description   string
+	GenreTypeId   int32
+	CreatorId     int32
+	IsPublic      bool
+	AllowComments bool
+	IsForSale     bool
+	PriceInRobux  int64
+	Sales         int32
+	Favorited     int32
+	Created       time.Time
+	Updated       time.Time
+	// Versions of the asset, in order of creation. The last version is the
+	// current version.
+	Versions []*AssetVersion
+}
+
+// AssetVersion is a single version of an Asset.
+type AssetVersion struct {
+	Id        int64
+	Number    int
+	Content   []byte
+	CreatorId int32
+	Created   time.Time
+}
+
+// Trade is an order placed on the currency exchange of a Server.
+type Trade struct {
+	UserId  int32
+	Have    string
+	HaveAmt int64
+	Want    string
+	WantAmt int64
+	Limit   bool
+	Split   bool
+}
+
+// Server is a fake ROBLOX website. The exported fields hold the state of the
+// server, and may be inspected or modified by tests while holding the lock
+// with Lock and Unlock.
+type Server struct {
+	*httptest.Server
+
+	// BaseDomain is the domain that clients returned by Client use.
+	BaseDomain string
+	// If Captcha is not empty, then logging in requires a captcha, whose
+	// solution is the value of Captcha.
+	Captcha string
+	// If TwoStepCode is not empty, then logging in requires a two-step
+	// verification code, whose value is TwoStepCode.
+	TwoStepCode string
+	// CSRFToken is the token required by mutating API requests.
+	CSRFToken string
+	// CDNHost is the host that asset downloads are redirected to.
+	CDNHost string
+	// MaxUploadSize is the maximum size of uploaded content, in bytes. If
+	// 0, then the size is not limited.
+	MaxUploadSize int64
+	// If Gzip is true, then responses are compressed with gzip when the
+	// request accepts it. Uploads compressed with gzip are always accepted.
+	Gzip bool
+
+	Users       map[int32]*User
+	Assets      map[int64]*Asset
+	Sets        map[int32][]int64
+	GroupShouts map[int32]string
+	GroupWalls  map[int32][]string
+	Trades      []Trade
+
+	mu       sync.Mutex
+	sessions map[string]int32
+	nextUser int32
+	nextId   int64
+}
+
+// NewServer starts and returns a new Server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		BaseDomain:  "roblox.com",
+		CSRFToken:   "rbxwebtest-csrf",
+		CDNHost:     "c0.rbxcdn.com",
+		Users:       make(map[int32]*User),
+		Assets:      make(map[int64]*Asset),
+		Sets:        make(map[int32][]int64),
+		GroupShouts: make(map[int32]string),
+		GroupWalls:  make(map[int32][]string),
+		sessions:    make(map[string]int32),
+		nextUser:    1,
+		nextId:      1000,
+	}
+	s.Server = httptest.NewServer(s.handler())
+	return s
+}
+
+// Lock locks the state of the server.
+func (s *Server) Lock() {
+	s.mu.Lock()
+}
+
+// Unlock unlocks the state of the server.
+func (s *Server) Unlock() {
+	s.mu.Unlock()
+}
+
+// AddUser adds a new user to the server.
+func (s *Server) AddUser(name string, password string) *User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := &User{Id: s.nextUser, Name: name, Password: password}
+	s.nextUser++
+	s.Users[user.Id] = user
+	return user
+}
+
+// AddAsset adds a new asset to the server, with `content` as its first
+// version.
+func (s *Server) AddAsset(creatorId int32, typeId int32, name string, content []byte) *Asset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addAsset(creatorId, typeId, name, content)
+}
+
+func (s *Server) addAsset(creatorId int32, typeId int32, name string, content []byte) *Asset {
+	now := time.Now().UTC()
+	asset := &Asset{
+		Id:        s.newId(),
+		TypeId:    typeId,
+		Name:      name,
+		CreatorId: creatorId,
+		Created:   now,
+		Updated:   now,
+	}
+	s.Assets[asset.Id] = asset
+	s.addVersion(asset, creatorId, content)
+	return asset
+}
+
+// addVersion adds a new version of an asset.
+func (s *Server) addVersion(asset *Asset, creatorId int32, content []byte) *AssetVersion {
+	version := &AssetVersion{
+		Id:        s.newId(),
+		Number:    len(asset.Versions) + 1,
+		Content:   content,
+		CreatorId: creatorId,
+		Created:   time.Now().UTC(),
+	}
+	asset.Versions = append(asset.Versions, version)
+	asset.Updated = version.Created
+	return version
+}
+
+// newId returns a new id for an asset or asset version.
+func (s *Server) newId() int64 {
+	id := s.nextId
+	s.nextId++
+	return id
+}
+
+// findVersion returns the asset version with the given id.
+func (s *Server) findVersion(id int64) (*Asset, *AssetVersion) {
+	for _, asset := range s.Assets {
+		for _, version := range asset.Versions {
+			if version.Id == id {
+				return asset, version
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Session returns the value of a new security cookie that logs in as the
+// given user. It can be used with rbxweb.Client.LoginCookie.
+func (s *Server) Session(userId int32) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newSession(userId)
+}
+
+func (s *Server) newSession(userId int32) string {
+	cookie := "rbxwebtest-session-" + strconv.FormatInt(int64(userId), 10) + "-" + strconv.FormatInt(s.newId(), 10)
+	s.sessions[cookie] = userId
+	return cookie
+}
+
+// ExpireSessions invalidates every session on the server.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]int32)
+}
+
+// Client returns a new client whose requests are all sent to the server. It
+// replaces the Client method of the embedded httptest.Server.
+func (s *Server) Client() *rbxweb.Client {
+	client := rbxweb.NewClient()
+	client.BaseDomain = s.BaseDomain
+	client.Jar = rbxweb.NewCookieJar()
+	u, _ := url.Parse(s.URL)
+	client.Transport = &transport{host: u.Host, base: s.Server.Client().Transport}
+	return client
+}
+
+// transport sends every request to a single host, while preserving the
+// original host in the Host header. Responses refer to the original request,
+// so that they report the emulated host.
+type transport struct {
+	host string
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = "http"
+	r.URL.Host = t.host
+	r.Host = req.URL.Host
+	resp, err := t.base.RoundTrip(r)
+	if resp != nil {
+		resp.Request = req
+	}
+	return resp, err
+}
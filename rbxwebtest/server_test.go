@@ -0,0 +1,191 @@
+package rbxwebtest_test
+
+import (
+	"errors"
+	"github.com/anaminus/rbxweb"
+	"github.com/anaminus/rbxweb/currency"
+	"github.com/anaminus/rbxweb/group"
+	"github.com/anaminus/rbxweb/rbxwebtest"
+	"github.com/anaminus/rbxweb/set"
+	"github.com/anaminus/rbxweb/user"
+	"testing"
+)
+
+// login returns a client of `s` that is logged in as a new user.
+func login(t *testing.T, s *rbxwebtest.Server) (*rbxweb.Client, *rbxwebtest.User) {
+	t.Helper()
+	u := s.AddUser("bob", "hunter2")
+	client := s.Client()
+	if err := client.Login("bob", "hunter2"); err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	return client, u
+}
+
+func TestLogin(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	client, u := login(t, s)
+
+	session, err := client.CheckSession()
+	if err != nil {
+		t.Fatalf("check session failed: %v", err)
+	}
+	if session.Id != u.Id || session.Name != "bob" {
+		t.Errorf("expected user %d bob, got %+v", u.Id, session)
+	}
+	if err := client.Login("bob", "hunter2"); err != rbxweb.ErrLoggedIn {
+		t.Errorf("expected ErrLoggedIn, got %v", err)
+	}
+
+	// Logging out requires a CSRF token, which is obtained by replaying the
+	// request.
+	if err := client.Logout(); err != nil {
+		t.Fatalf("logout failed: %v", err)
+	}
+	if _, err := client.CheckSession(); err != rbxweb.ErrNotLoggedIn {
+		t.Errorf("expected ErrNotLoggedIn after logout, got %v", err)
+	}
+}
+
+func TestLoginInvalidCredentials(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2")
+
+	err := s.Client().Login("bob", "wrong")
+	if !rbxweb.IsInvalidCredentials(err) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	if rbxweb.IsCaptchaRequired(err) || rbxweb.IsTwoStepRequired(err) {
+		t.Errorf("invalid credentials matched a challenge: %v", err)
+	}
+}
+
+func TestLoginChallenges(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2")
+	s.Captcha = "captcha-solution"
+	s.TwoStepCode = "123456"
+
+	client := s.Client()
+	err := client.Login("bob", "hunter2")
+	var chal *rbxweb.Challenge
+	if !errors.As(err, &chal) || chal.Type != rbxweb.ChallengeCaptcha {
+		t.Fatalf("expected captcha, got %v", err)
+	}
+	err = client.ResolveChallenge(chal, s.Captcha)
+	if !errors.As(err, &chal) || chal.Type != rbxweb.ChallengeTwoStep {
+		t.Fatalf("expected two-step verification, got %v", err)
+	}
+	if err = client.ResolveChallenge(chal, s.TwoStepCode); err != nil {
+		t.Fatalf("resolving two-step verification failed: %v", err)
+	}
+	if _, err := client.CheckSession(); err != nil {
+		t.Errorf("check session failed: %v", err)
+	}
+}
+
+func TestSession(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	u := s.AddUser("bob", "hunter2")
+
+	client := s.Client()
+	client.LoginCookie(s.Session(u.Id))
+	if _, err := client.CheckSession(); err != nil {
+		t.Fatalf("check session failed: %v", err)
+	}
+	s.ExpireSessions()
+	if _, err := client.CheckSession(); !rbxweb.IsSessionExpired(err) {
+		t.Errorf("expected expired session, got %v", err)
+	}
+}
+
+func TestUser(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	client, u := login(t, s)
+	s.Lock()
+	u.Robux = 100
+	s.Unlock()
+
+	info, err := user.GetInfo(client)
+	if err != nil || info.UserID != u.Id || info.RobuxBalance != 100 {
+		t.Errorf("unexpected info %+v, %v", info, err)
+	}
+	if id, err := user.GetCurrentId(client); err != nil || id != u.Id {
+		t.Errorf("unexpected current id %d, %v", id, err)
+	}
+	if name, err := user.GetNameFromId(client, u.Id); err != nil || name != "bob" {
+		t.Errorf("unexpected name %q, %v", name, err)
+	}
+	if _, err := user.GetNameFromId(client, 999); !rbxweb.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	client, u := login(t, s)
+	a := s.AddAsset(u.Id, 10, "Model", nil)
+
+	if err := set.Add(client, a.Id, 5); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	if ids := s.Sets[5]; len(ids) != 1 || ids[0] != a.Id {
+		t.Errorf("unexpected set %v", ids)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	client, _ := login(t, s)
+
+	if !group.Shout(client, 7, "hello") {
+		t.Error("shout failed")
+	}
+	if !group.Wall(client, 7, "first") {
+		t.Error("wall post failed")
+	}
+	s.Lock()
+	defer s.Unlock()
+	if shout := s.GroupShouts[7]; shout != "hello" {
+		t.Errorf("unexpected shout %q", shout)
+	}
+	if wall := s.GroupWalls[7]; len(wall) != 1 || wall[0] != "first" {
+		t.Errorf("unexpected wall %v", wall)
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	s := rbxwebtest.NewServer()
+	defer s.Close()
+	client, u := login(t, s)
+
+	if err := currency.TradeTickets(client, 100, 10, true, false); err != nil {
+		t.Fatalf("trade tickets failed: %v", err)
+	}
+	if err := currency.TradeRobux(client, 5, 50, false, true); err != nil {
+		t.Fatalf("trade robux failed: %v", err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	expected := []rbxwebtest.Trade{
+		{UserId: u.Id, Have: "Tickets", HaveAmt: 100, Want: "Robux", WantAmt: 10, Limit: true},
+		{UserId: u.Id, Have: "Robux", HaveAmt: 5, Want: "Tickets", WantAmt: 50, Split: true},
+	}
+	if len(s.Trades) != len(expected) {
+		t.Fatalf("expected %d trades, got %d", len(expected), len(s.Trades))
+	}
+	for i, trade := range s.Trades {
+		if trade != expected[i] {
+			t.Errorf("trade %d: expected %+v, got %+v", i, expected[i], trade)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package rbxweb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how a Client retries failed requests.
+//
+// MaxAttempts is the total number of attempts made for a single request,
+// including the first. A value less than 2 disables retrying.
+//
+// The delay before the nth retry is MinBackoff doubled n-1 times, but no
+// more than MaxBackoff. Jitter is a fraction between 0 and 1 by which each
+// delay is randomly reduced, so that many clients do not retry in lockstep.
+// If a response with a status of 429 or 503 has a Retry-After header, then
+// the delay given by the header is used instead. If that delay is longer
+// than MaxBackoff, then the request is not retried, and the response is
+// returned as-is, so that the caller can decide whether to wait.
+//
+// StatusCodes lists the response status codes that cause a request to be
+// retried. RetryError reports whether an error returned by the transport
+// causes a request to be retried. If RetryError is nil, then network errors
+// are retried, except when the request's context is done.
+//
+// Only GET, HEAD and OPTIONS requests are retried by default, since other
+// requests may not be safe to repeat. Other requests may opt in by using a
+// context returned by RetryUnsafe. Requests whose body cannot be rewound are
+// never retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	Jitter      float64
+	StatusCodes []int
+	RetryError  func(err error) bool
+}
+
+// DefaultRetryPolicy is the retry policy used by NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+	Jitter:      0.2,
+	StatusCodes: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+type retryUnsafeKey struct{}
+
+// RetryUnsafe returns a context that allows a request that is not
+// idempotent, such as a POST, to be retried by the client's retry policy.
+// This should only be used for requests that are known to be safe to
+// repeat, such as an upload that replaces the contents of an asset.
+func RetryUnsafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryUnsafeKey{}, true)
+}
+
+// canRetry returns whether the policy may be applied to `req` at all.
+func canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "", "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	unsafe, _ := req.Context().Value(retryUnsafeKey{}).(bool)
+	return unsafe
+}
+
+// retryable returns whether the result of an attempt should be retried.
+func (policy *RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		if policy.RetryError != nil {
+			return policy.RetryError(err)
+		}
+		return isNetError(err)
+	}
+	for _, code := range policy.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isNetError returns whether `err` was caused by the network rather than by
+// the request itself.
+func isNetError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	// url.Error implements net.Error itself, so look at what it wraps.
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		err = uerr.Err
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr)
+}
+
+// backoff returns how long to wait before retrying after the given attempt,
+// starting from 0. `ok` is false if the response asks for a delay longer
+// than the policy allows.
+func (policy *RetryPolicy) backoff(attempt int, resp *http.Response) (d time.Duration, ok bool) {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if policy.MaxBackoff > 0 && after > policy.MaxBackoff {
+				return 0, false
+			}
+			return after, true
+		}
+	}
+	d = policy.MinBackoff
+	for i := 0; i < attempt && d < policy.MaxBackoff; i++ {
+		d = d * 2
+	}
+	if policy.MaxBackoff > 0 && d > policy.MaxBackoff {
+		d = policy.MaxBackoff
+	}
+	if policy.Jitter > 0 {
+		d = d - time.Duration(rand.Float64()*policy.Jitter*float64(d))
+	}
+	return d, true
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(v string) (d time.Duration, ok bool) {
+	if v == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		if n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d = time.Until(t); d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// doRetry sends `req`, retrying it according to the client's retry policy.
+func (client *Client) doRetry(req *http.Request) (resp *http.Response, err error) {
+	policy := client.Retry
+	if policy == nil || policy.MaxAttempts < 2 || !canRetry(req) {
+		return client.send(req)
+	}
+
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.GetBody != nil {
+			r = req.Clone(ctx)
+			if r.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = client.send(r)
+		if attempt+1 >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(resp, err) {
+			return resp, err
+		}
+
+		wait, ok := policy.backoff(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
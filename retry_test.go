@@ -0,0 +1,125 @@
+package rbxweb_test
+
+import (
+	"context"
+	"github.com/anaminus/rbxweb"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRetryClient returns a client of a server that responds with `status` to
+// the first `failures` requests, and counts the requests it receives.
+func newRetryClient(t *testing.T, failures int32, status int, retryAfter string) (client *rbxweb.Client, requests *int32) {
+	requests = new(int32)
+	client = newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if atomic.AddInt32(requests, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	return client, requests
+}
+
+func TestRetry(t *testing.T) {
+	client, requests := newRetryClient(t, 2, http.StatusServiceUnavailable, "0")
+	url := client.GetURL(`www`, `/`, nil)
+	resp, err := client.Get(url)
+	if err = client.AssertResp(resp, err); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	resp.Body.Close()
+	if n := atomic.LoadInt32(requests); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	client, requests := newRetryClient(t, 100, http.StatusBadGateway, "")
+	url := client.GetURL(`www`, `/`, nil)
+	resp, err := client.Get(url)
+	if err = client.AssertResp(resp, err); err == nil {
+		t.Fatal("expected get to fail")
+	}
+	if n := atomic.LoadInt32(requests); n != int32(client.Retry.MaxAttempts) {
+		t.Errorf("expected %d attempts, got %d", client.Retry.MaxAttempts, n)
+	}
+
+	atomic.StoreInt32(requests, 0)
+	client.Retry = nil
+	resp, err = client.Get(url)
+	if err = client.AssertResp(resp, err); err == nil {
+		t.Fatal("expected get to fail")
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected 1 attempt without a policy, got %d", n)
+	}
+}
+
+func TestRetryUnsafe(t *testing.T) {
+	client, requests := newRetryClient(t, 2, http.StatusServiceUnavailable, "0")
+	url := client.GetURL(`www`, `/`, nil)
+
+	// Requests that are not idempotent are not retried unless allowed.
+	resp, err := client.Post(url, "text/plain", strings.NewReader("x"))
+	if err = client.AssertResp(resp, err); err == nil {
+		t.Fatal("expected post to fail")
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected 1 attempt, got %d", n)
+	}
+
+	ctx := rbxweb.RetryUnsafe(context.Background())
+	resp, err = client.PostContext(ctx, url, "text/plain", strings.NewReader("x"))
+	if err = client.AssertResp(resp, err); err != nil {
+		t.Fatalf("unsafe post failed: %v", err)
+	}
+	resp.Body.Close()
+	if n := atomic.LoadInt32(requests); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	client, _ := newRetryClient(t, 100, http.StatusServiceUnavailable, "60")
+	client.Retry.MaxBackoff = time.Minute
+	url := client.GetURL(`www`, `/`, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.GetContext(ctx, url); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("retry was not canceled")
+	}
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	client, requests := newRetryClient(t, 100, http.StatusTooManyRequests, "3600")
+	url := client.GetURL(`www`, `/`, nil)
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	resp.Body.Close()
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("waited for Retry-After beyond MaxBackoff")
+	}
+	// The response is returned so that the caller can see the delay.
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "3600" {
+		t.Errorf("unexpected response %d, Retry-After %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected 1 attempt, got %d", n)
+	}
+}
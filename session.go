@@ -0,0 +1,258 @@
+package rbxweb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/anaminus/rbxweb/internal/atomicfile"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInsecureSessionFile is returned by LoadSessionFile when the session file
+// may be read or written by users other than its owner.
+var ErrInsecureSessionFile = errors.New("session file is accessible by other users")
+
+// The version of the session format written by SaveSession.
+const sessionVersion = 1
+
+// session is the stored form of a client's cookies.
+type session struct {
+	Version int
+	Domain  string
+	Cookies []sessionCookie
+}
+
+// sessionCookie is the stored form of a cookie. If HostOnly is true, then
+// the cookie is sent only to Domain, and not to its subdomains. Expires is
+// the zero time for a cookie that lasts only for the session.
+type sessionCookie struct {
+	Name     string
+	Value    string
+	Domain   string
+	HostOnly bool
+	Path     string
+	Secure   bool
+	HttpOnly bool
+	Expires  time.Time
+}
+
+// url returns the URL that the cookie is set from.
+func (c sessionCookie) url() *url.URL {
+	return &url.URL{Scheme: "https", Host: c.Domain, Path: c.Path}
+}
+
+// cookieJar is a cookie jar that remembers the attributes of the cookies it
+// receives, which http.CookieJar does not expose.
+type cookieJar struct {
+	*cookiejar.Jar
+	mu      sync.Mutex
+	cookies map[string]sessionCookie
+}
+
+// NewCookieJar returns a cookie jar that remembers the domain, path, and
+// other attributes of its cookies, so that they are stored by SaveSession.
+// A client is given such a jar when it logs in without one. With any other
+// jar, SaveSession can only store each cookie as belonging to the host it
+// was found at.
+func NewCookieJar() http.CookieJar {
+	jar, _ := cookiejar.New(&cookiejar.Options{})
+	return &cookieJar{Jar: jar, cookies: make(map[string]sessionCookie)}
+}
+
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.Jar.SetCookies(u, cookies)
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, c := range cookies {
+		sc := sessionCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   strings.ToLower(strings.TrimPrefix(c.Domain, ".")),
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		}
+		if sc.Domain == "" {
+			sc.Domain = strings.ToLower(u.Hostname())
+			sc.HostOnly = true
+		}
+		if sc.Path == "" || sc.Path[0] != '/' {
+			// The default path is the directory of the request path.
+			sc.Path = "/"
+			if i := strings.LastIndex(u.Path, "/"); i > 0 {
+				sc.Path = u.Path[:i]
+			}
+		}
+		key := sc.Name + ";" + sc.Domain + ";" + sc.Path
+		switch {
+		case c.MaxAge < 0:
+			delete(j.cookies, key)
+			continue
+		case c.MaxAge > 0:
+			sc.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			if !c.Expires.After(now) {
+				delete(j.cookies, key)
+				continue
+			}
+			sc.Expires = c.Expires
+		}
+		j.cookies[key] = sc
+	}
+}
+
+// sessionCookies returns the stored form of each cookie that is still held
+// by the jar.
+func (j *cookieJar) sessionCookies() []sessionCookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var cookies []sessionCookie
+	for _, sc := range j.cookies {
+		// The jar may have rejected, expired, or replaced the cookie.
+		for _, c := range j.Jar.Cookies(sc.url()) {
+			if c.Name == sc.Name && c.Value == sc.Value {
+				cookies = append(cookies, sc)
+				break
+			}
+		}
+	}
+	sort.Slice(cookies, func(a, b int) bool {
+		if cookies[a].Domain != cookies[b].Domain {
+			return cookies[a].Domain < cookies[b].Domain
+		}
+		return cookies[a].Name < cookies[b].Name
+	})
+	return cookies
+}
+
+// ensureJar gives the client a cookie jar if it does not have one.
+func (client *Client) ensureJar() {
+	if client.Jar == nil {
+		client.Jar = NewCookieJar()
+	}
+}
+
+// sessionURLs returns the URLs whose cookies make up the client's session.
+func (client *Client) sessionURLs() []*url.URL {
+	var urls []*url.URL
+	for _, sub := range []string{``, `www`, `api`} {
+		u, err := url.Parse(client.GetSecureURL(sub, `/`, nil))
+		if err == nil {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// SaveSession writes the client's session cookies, such as .ROBLOSECURITY,
+// to `w` as JSON. The written session can be restored later with
+// LoadSession, allowing the client to stay logged in between runs.
+//
+// The attributes of each cookie, such as its domain and expiry, are stored
+// if the client's jar was created by NewCookieJar. Otherwise, each cookie is
+// stored as belonging only to the host it was found at.
+//
+// The session contains credentials for the logged-in account, and should be
+// stored with care.
+func (client *Client) SaveSession(w io.Writer) (err error) {
+	s := session{
+		Version: sessionVersion,
+		Domain:  client.BaseDomain,
+		Cookies: []sessionCookie{},
+	}
+	if jar, ok := client.Jar.(*cookieJar); ok {
+		s.Cookies = append(s.Cookies, jar.sessionCookies()...)
+	} else if client.Jar != nil {
+		for _, u := range client.sessionURLs() {
+			for _, cookie := range client.Jar.Cookies(u) {
+				s.Cookies = append(s.Cookies, sessionCookie{
+					Name:     cookie.Name,
+					Value:    cookie.Value,
+					Domain:   u.Hostname(),
+					HostOnly: true,
+					Path:     `/`,
+					HttpOnly: true,
+				})
+			}
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(s)
+}
+
+// LoadSession reads a session written by SaveSession from `r`, and adds its
+// cookies to the client's cookie jar, with the attributes they were saved
+// with. Cookies that have expired since are skipped. An error is returned if
+// the session was saved by a client with a different BaseDomain.
+func (client *Client) LoadSession(r io.Reader) (err error) {
+	var s session
+	if err = json.NewDecoder(r).Decode(&s); err != nil {
+		return errors.New("JSON decode failed: " + err.Error())
+	}
+	if s.Version != sessionVersion {
+		return fmt.Errorf("unsupported session version %d", s.Version)
+	}
+	if s.Domain != client.BaseDomain {
+		return fmt.Errorf("session is for domain %q, not %q", s.Domain, client.BaseDomain)
+	}
+
+	client.ensureJar()
+	now := time.Now()
+	for _, c := range s.Cookies {
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			continue
+		}
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			Expires:  c.Expires,
+		}
+		if !c.HostOnly {
+			cookie.Domain = c.Domain
+		}
+		client.Jar.SetCookies(c.url(), []*http.Cookie{cookie})
+	}
+	return nil
+}
+
+// SaveSessionFile is similar to SaveSession, but writes to a file name. The
+// file is created with permissions that allow only the owner to read and
+// write it, and is replaced atomically.
+func (client *Client) SaveSessionFile(filename string) (err error) {
+	return atomicfile.Write(filename, 0600, client.SaveSession)
+}
+
+// LoadSessionFile is similar to LoadSession, but reads from a file name.
+// Except on Windows, ErrInsecureSessionFile is returned if the file is
+// accessible by users other than its owner.
+func (client *Client) LoadSessionFile(filename string) (err error) {
+	var file *os.File
+	if file, err = os.Open(filename); err != nil {
+		return err
+	}
+	defer file.Close()
+	if runtime.GOOS != "windows" {
+		stat, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if stat.Mode().Perm()&0077 != 0 {
+			return ErrInsecureSessionFile
+		}
+	}
+	return client.LoadSession(file)
+}
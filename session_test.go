@@ -0,0 +1,148 @@
+package rbxweb_test
+
+import (
+	"bytes"
+	"github.com/anaminus/rbxweb"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cookieNames(cookies []*http.Cookie) string {
+	names := make([]string, len(cookies))
+	for i, c := range cookies {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func mustParse(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+var (
+	wwwURL      = mustParse("https://www.roblox.com/")
+	apiURL      = mustParse("https://api.roblox.com/")
+	insecureURL = mustParse("http://www.roblox.com/")
+)
+
+func TestSessionRoundTrip(t *testing.T) {
+	client := rbxweb.NewClient()
+	client.Jar = rbxweb.NewCookieJar()
+	client.Jar.SetCookies(wwwURL, []*http.Cookie{
+		{Name: "host", Value: "a", Path: "/", Secure: true},
+		{Name: "wide", Value: "b", Domain: ".roblox.com", MaxAge: 3600},
+		{Name: "expired", Value: "c", Expires: time.Now().Add(-time.Hour)},
+		{Name: "removed", Value: "d", MaxAge: -1},
+	})
+	var buf bytes.Buffer
+	if err := client.SaveSession(&buf); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	restored := rbxweb.NewClient()
+	if err := restored.LoadSession(&buf); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if names := cookieNames(restored.Jar.Cookies(wwwURL)); names != "host,wide" && names != "wide,host" {
+		t.Errorf("unexpected cookies for www: %s", names)
+	}
+	// A host-only cookie must not become a domain-wide cookie.
+	if names := cookieNames(restored.Jar.Cookies(apiURL)); names != "wide" {
+		t.Errorf("unexpected cookies for api: %s", names)
+	}
+	// A secure cookie must not be sent over http.
+	if names := cookieNames(restored.Jar.Cookies(insecureURL)); names != "wide" {
+		t.Errorf("unexpected cookies for http: %s", names)
+	}
+}
+
+func TestSessionForeignJar(t *testing.T) {
+	client := rbxweb.NewClient()
+	client.Jar, _ = cookiejar.New(nil)
+	client.Jar.SetCookies(wwwURL, []*http.Cookie{{Name: "host", Value: "a"}})
+	var buf bytes.Buffer
+	if err := client.SaveSession(&buf); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	restored := rbxweb.NewClient()
+	if err := restored.LoadSession(&buf); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if names := cookieNames(restored.Jar.Cookies(wwwURL)); names != "host" {
+		t.Errorf("unexpected cookies for www: %s", names)
+	}
+	if names := cookieNames(restored.Jar.Cookies(apiURL)); names != "" {
+		t.Errorf("expected cookie to stay host-only, got %s", names)
+	}
+}
+
+func TestLoadSessionErrors(t *testing.T) {
+	client := rbxweb.NewClient()
+	if err := client.LoadSession(strings.NewReader(`{"Version":99,"Domain":"roblox.com"}`)); err == nil {
+		t.Error("expected unsupported version to fail")
+	}
+	if err := client.LoadSession(strings.NewReader(`{"Version":1,"Domain":"gametest.robloxlabs.com"}`)); err == nil {
+		t.Error("expected domain mismatch to fail")
+	}
+	if err := client.LoadSession(strings.NewReader(`not json`)); err == nil {
+		t.Error("expected invalid JSON to fail")
+	}
+}
+
+func TestSessionFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "session.json")
+	client := rbxweb.NewClient()
+	client.Jar = rbxweb.NewCookieJar()
+	client.Jar.SetCookies(wwwURL, []*http.Cookie{{Name: ".ROBLOSECURITY", Value: "secret"}})
+	if err := client.SaveSessionFile(filename); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		stat, err := os.Stat(filename)
+		if err != nil {
+			t.Fatalf("stat failed: %v", err)
+		}
+		if perm := stat.Mode().Perm(); perm != 0600 {
+			t.Errorf("expected permissions 0600, got %o", perm)
+		}
+	}
+	// Saving again replaces the file.
+	if err := client.SaveSessionFile(filename); err != nil {
+		t.Fatalf("second save failed: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(filename)); len(entries) != 1 {
+		t.Errorf("expected only the session file, got %d files", len(entries))
+	}
+
+	restored := rbxweb.NewClient()
+	if err := restored.LoadSessionFile(filename); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if names := cookieNames(restored.Jar.Cookies(wwwURL)); names != ".ROBLOSECURITY" {
+		t.Errorf("unexpected cookies: %s", names)
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(filename, 0644); err != nil {
+			t.Fatalf("chmod failed: %v", err)
+		}
+		if err := rbxweb.NewClient().LoadSessionFile(filename); err != rbxweb.ErrInsecureSessionFile {
+			t.Errorf("expected ErrInsecureSessionFile, got %v", err)
+		}
+	}
+	if err := rbxweb.NewClient().LoadSessionFile(filename + ".missing"); !os.IsNotExist(err) {
+		t.Errorf("expected missing file error, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@
 package set
 
 import (
+	"context"
 	"github.com/anaminus/rbxweb"
 	"net/url"
 	"strconv"
@@ -12,13 +13,23 @@ import (
 //
 // This function requires the client to be logged in.
 func Add(client *rbxweb.Client, assetId int64, setId int32) (err error) {
+	return AddContext(context.Background(), client, assetId, setId)
+}
+
+// AddContext is similar to Add, but the request is canceled when `ctx` is
+// done.
+func AddContext(ctx context.Context, client *rbxweb.Client, assetId int64, setId int32) (err error) {
 	query := url.Values{
 		"rqtype":  {"addtoset"},
 		"assetId": {strconv.FormatInt(assetId, 10)},
 		"setId":   {strconv.FormatInt(int64(setId), 10)},
 	}
 
-	resp, err := client.Post(client.GetURL(`www`, `/Sets/SetHandler.ashx`, query), "", nil)
+	req, err := client.NewRequest(ctx, rbxweb.EndpointSetHandler, query, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return err
 	}
@@ -3,9 +3,10 @@ package user
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"net/http"
+	"github.com/anaminus/rbxweb"
 	"net/url"
 	"strconv"
 )
@@ -23,8 +24,18 @@ type Info struct {
 // GetInfo returns information about the current user.
 //
 // This function requires the client to be logged in.
-func GetInfo(client *Client) (info Info, err error) {
-	resp, err := client.Get(client.GetURL(`www`, `/MobileAPI/UserInfo`, nil))
+func GetInfo(client *rbxweb.Client) (info Info, err error) {
+	return GetInfoContext(context.Background(), client)
+}
+
+// GetInfoContext is similar to GetInfo, but the request is canceled when
+// `ctx` is done.
+func GetInfoContext(ctx context.Context, client *rbxweb.Client) (info Info, err error) {
+	req, err := client.NewRequest(ctx, rbxweb.EndpointUserInfo, nil, nil)
+	if err != nil {
+		return info, err
+	}
+	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return info, err
 	}
@@ -39,8 +50,18 @@ func GetInfo(client *Client) (info Info, err error) {
 // GetCurrentId returns the id of the user currently logged in.
 //
 // This function requires the client to be logged in.
-func GetCurrentId(client *Client) (id int32, err error) {
-	resp, err := client.Get(client.GetURL(`www`, `/Game/GetCurrentUser.ashx`, nil))
+func GetCurrentId(client *rbxweb.Client) (id int32, err error) {
+	return GetCurrentIdContext(context.Background(), client)
+}
+
+// GetCurrentIdContext is similar to GetCurrentId, but the request is
+// canceled when `ctx` is done.
+func GetCurrentIdContext(ctx context.Context, client *rbxweb.Client) (id int32, err error) {
+	req, err := client.NewRequest(ctx, rbxweb.EndpointCurrentUser, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return 0, err
 	}
@@ -50,20 +71,29 @@ func GetCurrentId(client *Client) (id int32, err error) {
 	r.ReadFrom(resp.Body)
 	n, err := strconv.ParseInt(r.String(), 10, 32)
 	if err != nil {
-		return 0, errors.New("user is not authorized")
+		return 0, rbxweb.ErrUnauthorized
 	}
 	return int32(n), nil
 }
 
 // GetIdFromName returns a user id from a user name.
-func GetIdFromName(client *Client, name string) (id int32, err error) {
+func GetIdFromName(client *rbxweb.Client, name string) (id int32, err error) {
+	return GetIdFromNameContext(context.Background(), client, name)
+}
+
+// GetIdFromNameContext is similar to GetIdFromName, but the request is
+// canceled when `ctx` is done.
+func GetIdFromNameContext(ctx context.Context, client *rbxweb.Client, name string) (id int32, err error) {
 	if name == "" {
 		return 0, errors.New("name not specified")
 	}
 	query := url.Values{
 		"UserName": {name},
 	}
-	req, _ := http.NewRequest("HEAD", client.GetURL(`www`, `/User.aspx`, query), nil)
+	req, err := client.NewRequest(ctx, rbxweb.EndpointUserProfile, query, nil)
+	if err != nil {
+		return 0, err
+	}
 	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return 0, err
@@ -79,11 +109,21 @@ func GetIdFromName(client *Client, name string) (id int32, err error) {
 }
 
 // GetNameFromId returns a user name from a user id.
-func GetNameFromId(client *Client, id int32) (name string, err error) {
+func GetNameFromId(client *rbxweb.Client, id int32) (name string, err error) {
+	return GetNameFromIdContext(context.Background(), client, id)
+}
+
+// GetNameFromIdContext is similar to GetNameFromId, but the request is
+// canceled when `ctx` is done.
+func GetNameFromIdContext(ctx context.Context, client *rbxweb.Client, id int32) (name string, err error) {
 	if id == 0 {
 		return "", errors.New("id not specified")
 	}
-	resp, err := client.Get(client.GetURL(`api`, `/users/`+strconv.FormatInt(int64(id), 10), nil))
+	req, err := client.NewRequest(ctx, rbxweb.EndpointUserName, nil, nil, id)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
 	if err = client.AssertResp(resp, err); err != nil {
 		return "", err
 	}